@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"github.com/IkezawaYuki/lucky-strike/internal/command"
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
 	"github.com/IkezawaYuki/lucky-strike/internal/terminal"
 	"github.com/hashicorp/terraform-svchost/disco"
 	"github.com/hashicorp/terraform/command/cliconfig"
@@ -18,10 +21,44 @@ var HiddenCommands map[string]struct{}
 var Ui cli.Ui
 
 func initCommands(
+	ctx context.Context,
 	originalWorkingDir string,
 	streams *terminal.Streams,
 	config *cliconfig.Config,
 	services *disco.Disco,
-	providerSrc getproviders.Source,
+	inAutomation bool,
+	noColor bool,
+	noPager bool,
+) {
+	meta := command.Meta{
+		OriginalWorkingDir: originalWorkingDir,
+		CallerContext:      ctx,
+		Streams:            streams,
+		Ui:                 Ui,
+		Services:           services,
 
-)
+		RunningInAutomation: inAutomation,
+		NoColor:             noColor,
+		NoPager:             noPager,
+	}
+
+	Commands = map[string]cli.CommandFactory{
+		"version": func() (cli.Command, error) {
+			return &command.VersionCommand{
+				Meta:              meta,
+				Version:           Version,
+				VersionPrerelease: VersionPrerelease,
+				Platform:          getproviders.CurrentPlatform,
+				CheckFunc:         releaseIndexCheck(versionString()),
+			}, nil
+		},
+	}
+
+	PrimaryCommands = []string{}
+
+	HiddenCommands = map[string]struct{}{}
+}
+
+func credentialsSource(config *cliconfig.Config) (*cliconfig.CredentialsSource, error) {
+	return config.CredentialsSource(nil)
+}
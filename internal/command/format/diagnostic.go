@@ -0,0 +1,98 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/IkezawaYuki/lucky-strike/internal/terminal"
+	"github.com/IkezawaYuki/lucky-strike/internal/tfdiags"
+	"github.com/mitchellh/colorstring"
+	"strings"
+)
+
+func Diagnostic(diag tfdiags.Diagnostic, sources map[string][]byte, color *colorstring.Colorize, width int) string {
+	var buf bytes.Buffer
+
+	var leftRuleLine, leftRuleStart, leftRuleEnd string
+	var leftRuleWidth int
+
+	switch diag.Severity {
+	case tfdiags.Error:
+		buf.WriteString(color.Color("[bold][red]Error: [reset]"))
+		leftRuleLine = color.Color("[red]│[reset] ")
+		leftRuleStart = color.Color("[red]╷[reset]")
+		leftRuleEnd = color.Color("[red]╵[reset]")
+		leftRuleWidth = 2
+	case tfdiags.Warning:
+		buf.WriteString(color.Color("[bold][yellow]Warning: [reset]"))
+		leftRuleLine = color.Color("[yellow]│[reset] ")
+		leftRuleStart = color.Color("[yellow]╷[reset]")
+		leftRuleEnd = color.Color("[yellow]╵[reset]")
+		leftRuleWidth = 2
+	default:
+		buf.WriteString(color.Color("\n[reset]"))
+	}
+
+	fmt.Fprintf(&buf, color.Color("[bold]%s[reset]\n\n"), diag.Summary)
+
+	writeSourceSnippet(&buf, diag.Subject, sources, color)
+
+	if diag.Detail != "" {
+		paraWidth := width - leftRuleWidth - 1
+		lines := strings.Split(diag.Detail, "\n")
+		for _, line := range lines {
+			if !strings.HasPrefix(line, " ") && paraWidth > 0 {
+				line = terminal.Wrap(line, paraWidth, "")
+			}
+			fmt.Fprintf(&buf, "%s\n", line)
+		}
+	}
+
+	var ruleBuf strings.Builder
+	sc := bufio.NewScanner(&buf)
+	ruleBuf.WriteString(leftRuleStart)
+	ruleBuf.WriteByte('\n')
+	for sc.Scan() {
+		line := sc.Text()
+		prefix := leftRuleLine
+		if line == "" {
+			prefix = strings.TrimSpace(prefix)
+		}
+		ruleBuf.WriteString(prefix)
+		ruleBuf.WriteString(line)
+		ruleBuf.WriteByte('\n')
+	}
+	ruleBuf.WriteString(leftRuleEnd)
+	ruleBuf.WriteByte('\n')
+
+	return ruleBuf.String()
+}
+
+func writeSourceSnippet(buf *bytes.Buffer, subject *tfdiags.SourceRange, sources map[string][]byte, color *colorstring.Colorize) {
+	if subject == nil {
+		return
+	}
+
+	src, ok := sources[subject.Filename]
+	if !ok {
+		fmt.Fprintf(buf, "  on %s line %d:\n  (source code not available)\n\n", subject.Filename, subject.Start.Line)
+		return
+	}
+
+	fmt.Fprintf(buf, "  on %s line %d:\n", subject.Filename, subject.Start.Line)
+	for _, line := range subject.SourceLines(src) {
+		before := line.Text[:line.HighlightStart]
+		highlighted := line.Text[line.HighlightStart:line.HighlightEnd]
+		after := line.Text[line.HighlightEnd:]
+
+		if color.Disable {
+			fmt.Fprintf(buf, "%4d: %s\n", line.Number, line.Text)
+			if highlighted != "" {
+				fmt.Fprintf(buf, "      %s%s\n", strings.Repeat(" ", terminal.StringWidth(before)), strings.Repeat("^", terminal.StringWidth(highlighted)))
+			}
+			continue
+		}
+		fmt.Fprintf(buf, "%4d: %s%s%s\n", line.Number, before, color.Color("[underline]"+highlighted+"[reset]"), after)
+	}
+	buf.WriteByte('\n')
+}
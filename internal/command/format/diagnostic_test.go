@@ -0,0 +1,210 @@
+package format
+
+import (
+	"github.com/IkezawaYuki/lucky-strike/internal/tfdiags"
+	"github.com/mitchellh/colorstring"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostic(t *testing.T) {
+	sources := map[string][]byte{
+		"main.tf":  []byte("locals {\n  name = \"x\" + 1\n}\n"),
+		"wide.tf":  []byte("locals {\n  名前 = \"値\" + 1\n}\n"),
+		"multi.tf": []byte("locals {\n  a = [\n    1,\n  ]\n}\n"),
+		"crlf.tf":  []byte("locals {\r\n  name = \"x\" + 1\r\n}\r\n"),
+	}
+
+	tests := map[string]struct {
+		diag  tfdiags.Diagnostic
+		width int
+		want  []string
+	}{
+		"sourceless": {
+			diag: tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid configuration",
+				"The configuration is not valid, and this detail is long enough that it must wrap.",
+			),
+			width: 40,
+			want: []string{
+				"╷",
+				"│ Error: Invalid configuration",
+				"│",
+				"│ The configuration is not valid, and",
+				"│ this detail is long enough that it",
+				"│ must wrap.",
+				"╵",
+			},
+		},
+		"indented detail is not wrapped": {
+			diag: tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Deprecated",
+				"Use this instead:\n  some_very_long_replacement_that_must_not_wrap = true",
+			),
+			width: 40,
+			want: []string{
+				"╷",
+				"│ Warning: Deprecated",
+				"│",
+				"│ Use this instead:",
+				"│   some_very_long_replacement_that_must_not_wrap = true",
+				"╵",
+			},
+		},
+		"source snippet": {
+			diag: tfdiags.Diagnostic{
+				Severity: tfdiags.Error,
+				Summary:  "Invalid operand",
+				Subject: &tfdiags.SourceRange{
+					Filename: "main.tf",
+					Start:    tfdiags.SourcePos{Line: 2, Column: 10, Byte: 18},
+					End:      tfdiags.SourcePos{Line: 2, Column: 13, Byte: 21},
+				},
+			},
+			width: 78,
+			want: []string{
+				"╷",
+				"│ Error: Invalid operand",
+				"│",
+				"│   on main.tf line 2:",
+				"│    2:   name = \"x\" + 1",
+				"│                ^^^",
+				"│",
+				"╵",
+			},
+		},
+		"wide characters": {
+			diag: tfdiags.Diagnostic{
+				Severity: tfdiags.Error,
+				Summary:  "Invalid operand",
+				Subject: &tfdiags.SourceRange{
+					Filename: "wide.tf",
+					Start:    tfdiags.SourcePos{Line: 2, Column: 8, Byte: 20},
+					End:      tfdiags.SourcePos{Line: 2, Column: 11, Byte: 25},
+				},
+			},
+			width: 78,
+			want: []string{
+				"╷",
+				"│ Error: Invalid operand",
+				"│",
+				"│   on wide.tf line 2:",
+				"│    2:   名前 = \"値\" + 1",
+				"│                ^^^^",
+				"│",
+				"╵",
+			},
+		},
+		"multiple lines": {
+			diag: tfdiags.Diagnostic{
+				Severity: tfdiags.Error,
+				Summary:  "Invalid list",
+				Subject: &tfdiags.SourceRange{
+					Filename: "multi.tf",
+					Start:    tfdiags.SourcePos{Line: 2, Column: 7, Byte: 15},
+					End:      tfdiags.SourcePos{Line: 4, Column: 4, Byte: 28},
+				},
+			},
+			width: 78,
+			want: []string{
+				"╷",
+				"│ Error: Invalid list",
+				"│",
+				"│   on multi.tf line 2:",
+				"│    2:   a = [",
+				"│             ^",
+				"│    3:     1,",
+				"│       ^^^^^^",
+				"│    4:   ]",
+				"│       ^^^",
+				"│",
+				"╵",
+			},
+		},
+		"CRLF line endings": {
+			diag: tfdiags.Diagnostic{
+				Severity: tfdiags.Error,
+				Summary:  "Invalid operand",
+				Subject: &tfdiags.SourceRange{
+					Filename: "crlf.tf",
+					Start:    tfdiags.SourcePos{Line: 2, Column: 10, Byte: 19},
+					End:      tfdiags.SourcePos{Line: 2, Column: 19, Byte: 28},
+				},
+			},
+			width: 78,
+			want: []string{
+				"╷",
+				"│ Error: Invalid operand",
+				"│",
+				"│   on crlf.tf line 2:",
+				"│    2:   name = \"x\" + 1",
+				"│                ^^^^^^^",
+				"│",
+				"╵",
+			},
+		},
+		"source not available": {
+			diag: tfdiags.Diagnostic{
+				Severity: tfdiags.Warning,
+				Summary:  "Missing file",
+				Subject: &tfdiags.SourceRange{
+					Filename: "gone.tf",
+					Start:    tfdiags.SourcePos{Line: 3, Column: 1, Byte: 20},
+					End:      tfdiags.SourcePos{Line: 3, Column: 5, Byte: 24},
+				},
+			},
+			width: 78,
+			want: []string{
+				"╷",
+				"│ Warning: Missing file",
+				"│",
+				"│   on gone.tf line 3:",
+				"│   (source code not available)",
+				"│",
+				"╵",
+			},
+		},
+	}
+
+	color := &colorstring.Colorize{
+		Colors:  colorstring.DefaultColors,
+		Disable: true,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Diagnostic(test.diag, sources, color, test.width)
+			want := strings.Join(test.want, "\n") + "\n"
+			if got != want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestDiagnostic_color(t *testing.T) {
+	sources := map[string][]byte{
+		"main.tf": []byte("locals {\n  name = \"x\" + 1\n}\n"),
+	}
+	diag := tfdiags.Diagnostic{
+		Severity: tfdiags.Error,
+		Summary:  "Invalid operand",
+		Subject: &tfdiags.SourceRange{
+			Filename: "main.tf",
+			Start:    tfdiags.SourcePos{Line: 2, Column: 10, Byte: 18},
+			End:      tfdiags.SourcePos{Line: 2, Column: 13, Byte: 21},
+		},
+	}
+	color := &colorstring.Colorize{
+		Colors: colorstring.DefaultColors,
+	}
+
+	got := Diagnostic(diag, sources, color, 78)
+	if want := "   2:   name = \x1b[4m\"x\"\x1b[0m + 1\n"; !strings.Contains(got, want) {
+		t.Errorf("highlighted source line %q not found in\n%q", want, got)
+	}
+	if strings.Contains(got, "^") {
+		t.Errorf("caret line drawn although color is enabled:\n%s", got)
+	}
+}
@@ -0,0 +1,135 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	viewsjson "github.com/IkezawaYuki/lucky-strike/internal/command/views/json"
+	"github.com/IkezawaYuki/lucky-strike/internal/terminal"
+	"github.com/hashicorp/go-hclog"
+	"github.com/mitchellh/cli"
+	"sync"
+)
+
+// JSONUIVersion is the version of the machine-readable message schema
+// emitted by JSONUi. It is reported in the "ui" field of the version
+// message that starts every JSON stream. The minor version increases
+// when message types or fields are added, and the major version
+// increases for any change that could break an existing consumer.
+const JSONUIVersion = "1.0"
+
+var errJSONUiNoInput = errors.New("cannot prompt for input when the -json option is set")
+
+type JSONUi struct {
+	log hclog.Logger
+
+	mu          sync.Mutex
+	opening     viewsjson.Version
+	versionSent bool
+}
+
+var _ cli.Ui = (*JSONUi)(nil)
+
+// NewJSONUi returns a UI that writes JSON messages to stdout. The given
+// version is sent as the opening version message unless a command sends a
+// more detailed one with Version before any other message.
+func NewJSONUi(streams *terminal.Streams, opening viewsjson.Version) *JSONUi {
+	return &JSONUi{
+		log: hclog.New(&hclog.LoggerOptions{
+			Name:       "terraform.ui",
+			Output:     streams.Stdout.File,
+			JSONFormat: true,
+		}),
+		opening: opening,
+	}
+}
+
+// EnsureVersion sends the opening version message if nothing has been sent
+// yet, so that even a stream with no other messages starts with one.
+func (u *JSONUi) EnsureVersion() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.sendVersion(u.opening)
+}
+
+func (u *JSONUi) Ask(query string) (string, error) {
+	return "", errJSONUiNoInput
+}
+
+func (u *JSONUi) AskSecret(query string) (string, error) {
+	return "", errJSONUiNoInput
+}
+
+func (u *JSONUi) Output(msg string) {
+	u.EnsureVersion()
+	u.log.Info(msg, "type", viewsjson.MessageLog)
+}
+
+func (u *JSONUi) Info(msg string) {
+	u.EnsureVersion()
+	u.log.Info(msg, "type", viewsjson.MessageLog)
+}
+
+func (u *JSONUi) Warn(msg string) {
+	u.EnsureVersion()
+	u.log.Warn(msg, "type", viewsjson.MessageLog)
+}
+
+func (u *JSONUi) Error(msg string) {
+	u.EnsureVersion()
+	u.log.Error(msg, "type", viewsjson.MessageLog)
+}
+
+// Version sends the opening version message with the given details. It
+// does nothing if the opening message was already sent, since a stream
+// only ever has one.
+func (u *JSONUi) Version(v viewsjson.Version) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.sendVersion(v)
+}
+
+func (u *JSONUi) sendVersion(v viewsjson.Version) {
+	if u.versionSent {
+		return
+	}
+	u.versionSent = true
+	u.log.Info(
+		fmt.Sprintf("Terraform %s", v.Version),
+		"type", viewsjson.MessageVersion,
+		"ui", JSONUIVersion,
+		"version", v,
+	)
+}
+
+func (u *JSONUi) Diagnostic(diag *viewsjson.Diagnostic) {
+	u.EnsureVersion()
+	msg := fmt.Sprintf("%s: %s", diagnosticSeverityTitle(diag.Severity), diag.Summary)
+	switch diag.Severity {
+	case viewsjson.DiagnosticSeverityError:
+		u.log.Error(msg, "type", viewsjson.MessageDiagnostic, "diagnostic", diag)
+	case viewsjson.DiagnosticSeverityWarning:
+		u.log.Warn(msg, "type", viewsjson.MessageDiagnostic, "diagnostic", diag)
+	default:
+		u.log.Info(msg, "type", viewsjson.MessageDiagnostic, "diagnostic", diag)
+	}
+}
+
+func (u *JSONUi) InstallerEvent(msg string, ev *viewsjson.InstallerEvent) {
+	u.EnsureVersion()
+	if ev.Error != "" {
+		u.log.Error(msg, "type", viewsjson.MessageInstallerEvent, "installer", ev)
+		return
+	}
+	u.log.Info(msg, "type", viewsjson.MessageInstallerEvent, "installer", ev)
+}
+
+func diagnosticSeverityTitle(severity string) string {
+	switch severity {
+	case viewsjson.DiagnosticSeverityError:
+		return "Error"
+	case viewsjson.DiagnosticSeverityWarning:
+		return "Warning"
+	default:
+		return "Diagnostic"
+	}
+}
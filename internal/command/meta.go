@@ -0,0 +1,117 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
+	"github.com/IkezawaYuki/lucky-strike/internal/terminal"
+	"github.com/hashicorp/terraform-svchost/disco"
+	"github.com/mitchellh/cli"
+	"io/ioutil"
+	"log"
+)
+
+type Meta struct {
+	OriginalWorkingDir string
+
+	// CallerContext carries the span covering the whole command, so that
+	// spans started by the command are recorded as its children.
+	CallerContext context.Context
+
+	Streams *terminal.Streams
+
+	Ui cli.Ui
+
+	Services *disco.Disco
+
+	// ProviderSource is where commands find and fetch providers. Commands
+	// use it through providerSource, so that each request is traced.
+	ProviderSource getproviders.Source
+
+	// RunningInAutomation is set when the TF_IN_AUTOMATION environment
+	// variable is present, signalling that output is consumed by a wrapping
+	// script or CI system rather than a human at a terminal.
+	RunningInAutomation bool
+
+	// NoColor is set by the global -no-color option or the NO_COLOR
+	// environment variable.
+	NoColor bool
+
+	// NoPager is set by the global -no-pager option.
+	NoPager bool
+}
+
+func (m *Meta) Input() bool {
+	if m.RunningInAutomation {
+		return false
+	}
+	return m.Streams != nil && m.Streams.Stdin.IsTerminal()
+}
+
+func (m *Meta) Color() bool {
+	if m.RunningInAutomation || m.NoColor {
+		return false
+	}
+	return m.Streams != nil && m.Streams.Stdout.ColorDepth() != terminal.ColorNone
+}
+
+// ProgressEnabled reports whether progress is drawn in place on stdout,
+// rather than not at all.
+func (m *Meta) ProgressEnabled() bool {
+	if m.RunningInAutomation {
+		return false
+	}
+	return m.Streams != nil && m.Streams.Stdout.IsTerminal()
+}
+
+// OutputNextSteps shows hints about what the user might run next, which
+// are only noise to a script or CI system running Terraform.
+func (m *Meta) OutputNextSteps(msg string) {
+	if m.RunningInAutomation {
+		return
+	}
+	m.Ui.Output(msg)
+}
+
+// newProgress starts showing progress on stdout. Stop must be called on
+// the result once all of its tasks are done.
+func (m *Meta) newProgress() *terminal.Progress {
+	return terminal.NewProgress(m.Streams, terminal.ProgressOptions{
+		RunningInAutomation: m.RunningInAutomation,
+	})
+}
+
+// page shows text on stdout, through the user's pager if it is too long to
+// fit on their terminal.
+func (m *Meta) page(text string) {
+	if m.Streams == nil {
+		m.Ui.Output(text)
+		return
+	}
+	if err := m.Streams.Page(text, !m.RunningInAutomation && !m.NoPager); err != nil {
+		log.Printf("[WARN] Failed to run pager: %s", err)
+	}
+}
+
+// providerSource returns the ProviderSource wrapped to record each of its
+// requests as a span, or nil if there is none.
+func (m *Meta) providerSource() getproviders.Source {
+	if m.ProviderSource == nil {
+		return nil
+	}
+	return getproviders.NewTracingSource(m.ProviderSource)
+}
+
+func (m *Meta) callerContext() context.Context {
+	if m.CallerContext == nil {
+		return context.Background()
+	}
+	return m.CallerContext
+}
+
+func (m *Meta) defaultFlagSet(n string) *flag.FlagSet {
+	f := flag.NewFlagSet(n, flag.ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.Usage = func() {}
+	return f
+}
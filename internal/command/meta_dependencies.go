@@ -0,0 +1,14 @@
+package command
+
+import (
+	"github.com/IkezawaYuki/lucky-strike/internal/depsfile"
+	"github.com/IkezawaYuki/lucky-strike/internal/tfdiags"
+	"os"
+)
+
+func (m *Meta) lockedDependencies() (*depsfile.Locks, tfdiags.Diagnostics) {
+	if _, err := os.Stat(depsfile.LockFilePath); os.IsNotExist(err) {
+		return depsfile.NewLocks(), nil
+	}
+	return depsfile.LoadLocksFromFile(depsfile.LockFilePath)
+}
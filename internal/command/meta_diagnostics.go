@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+	"github.com/IkezawaYuki/lucky-strike/internal/command/format"
+	viewsjson "github.com/IkezawaYuki/lucky-strike/internal/command/views/json"
+	"github.com/IkezawaYuki/lucky-strike/internal/terminal"
+	"github.com/IkezawaYuki/lucky-strike/internal/tfdiags"
+	"github.com/mitchellh/colorstring"
+	"io/ioutil"
+	"log"
+)
+
+func (m *Meta) showDiagnostics(vals ...interface{}) {
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(vals...)
+	if len(diags) == 0 {
+		return
+	}
+
+	sources := diagnosticSources(diags)
+
+	if ui, ok := m.Ui.(*JSONUi); ok {
+		for _, diag := range diags {
+			ui.Diagnostic(viewsjson.NewDiagnostic(diag, sources))
+		}
+		return
+	}
+
+	stderr := m.Streams.Stderr
+	color := m.colorize(stderr)
+	for _, diag := range diags {
+		fmt.Fprint(stderr.File, format.Diagnostic(diag, sources, color, stderr.Columns()))
+	}
+}
+
+func (m *Meta) colorize(stream *terminal.OutputStream) *colorstring.Colorize {
+	return &colorstring.Colorize{
+		Colors:  colorstring.DefaultColors,
+		Disable: m.RunningInAutomation || m.NoColor || stream.ColorDepth() == terminal.ColorNone,
+		Reset:   true,
+	}
+}
+
+func diagnosticSources(diags tfdiags.Diagnostics) map[string][]byte {
+	sources := make(map[string][]byte)
+	for _, diag := range diags {
+		if diag.Subject == nil {
+			continue
+		}
+		filename := diag.Subject.Filename
+		if _, exists := sources[filename]; exists {
+			continue
+		}
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			log.Printf("[DEBUG] Cannot read %s for diagnostic source snippet: %s", filename, err)
+			continue
+		}
+		sources[filename] = src
+	}
+	return sources
+}
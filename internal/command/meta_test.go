@@ -0,0 +1,48 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
+	"github.com/hashicorp/terraform/addrs"
+	"testing"
+)
+
+type testProviderSource struct {
+	getproviders.Source
+
+	versions getproviders.VersionList
+	err      error
+}
+
+func (s *testProviderSource) AvailableVersions(ctx context.Context, provider addrs.Provider) (getproviders.VersionList, getproviders.Warnings, error) {
+	return s.versions, nil, s.err
+}
+
+func (s *testProviderSource) PackageMeta(ctx context.Context, provider addrs.Provider, version getproviders.Version, target getproviders.Platform) (getproviders.PackageMeta, error) {
+	return getproviders.PackageMeta{Provider: provider, Version: version, TargetPlatform: target}, s.err
+}
+
+func TestMetaProviderSource(t *testing.T) {
+	if got := (&Meta{}).providerSource(); got != nil {
+		t.Errorf("got %#v without a ProviderSource; want nil", got)
+	}
+
+	want := getproviders.VersionList{getproviders.MustParseVersion("3.30.0")}
+	m := &Meta{
+		ProviderSource: &testProviderSource{versions: want, err: errors.New("boom")},
+	}
+	src := m.providerSource()
+	if _, ok := src.(*getproviders.TracingSource); !ok {
+		t.Fatalf("provider source is %T; want *getproviders.TracingSource", src)
+	}
+
+	provider := addrs.NewDefaultProvider("aws")
+	got, _, err := src.AvailableVersions(context.Background(), provider)
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("wrong versions %v; want %v", got, want)
+	}
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("wrong error %v; want boom", err)
+	}
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"fmt"
+	"github.com/IkezawaYuki/lucky-strike/internal/terminal"
+	"github.com/mitchellh/cli"
+	"github.com/mitchellh/colorstring"
+)
+
+type StreamsUi struct {
+	Streams *terminal.Streams
+
+	// Color is the caller's overall permission to use color. Even when it
+	// is set, messages are only colorized if the stream they are written to
+	// can display color.
+	Color bool
+
+	// RunningInAutomation makes Ask and AskSecret fail rather than wait for
+	// an answer nobody will give.
+	RunningInAutomation bool
+}
+
+var _ cli.Ui = (*StreamsUi)(nil)
+
+func NewStreamsUi(streams *terminal.Streams, color, inAutomation bool) *StreamsUi {
+	return &StreamsUi{
+		Streams:             streams,
+		Color:               color,
+		RunningInAutomation: inAutomation,
+	}
+}
+
+func (u *StreamsUi) Ask(query string) (string, error) {
+	return u.Streams.Stdin.Prompt(u.Streams.Stdout, query, u.promptOptions())
+}
+
+func (u *StreamsUi) AskSecret(query string) (string, error) {
+	return u.Streams.Stdin.PromptSecret(u.Streams.Stdout, query, u.promptOptions())
+}
+
+func (u *StreamsUi) Output(msg string) {
+	u.write(u.Streams.Stdout, "", msg)
+}
+
+func (u *StreamsUi) Info(msg string) {
+	u.write(u.Streams.Stdout, "", msg)
+}
+
+func (u *StreamsUi) Warn(msg string) {
+	u.write(u.Streams.Stderr, "[yellow]", msg)
+}
+
+func (u *StreamsUi) Error(msg string) {
+	u.write(u.Streams.Stderr, "[red]", msg)
+}
+
+func (u *StreamsUi) Colorize(stream *terminal.OutputStream) *colorstring.Colorize {
+	return &colorstring.Colorize{
+		Colors:  colorstring.DefaultColors,
+		Disable: !u.Color || stream.ColorDepth() == terminal.ColorNone,
+		Reset:   true,
+	}
+}
+
+func (u *StreamsUi) write(stream *terminal.OutputStream, color, msg string) {
+	msg = terminal.Wrap(msg, stream.Columns(), "")
+	if color != "" {
+		msg = u.Colorize(stream).Color(color + msg)
+	}
+	fmt.Fprintln(stream.File, msg)
+}
+
+func (u *StreamsUi) promptOptions() terminal.PromptOptions {
+	return terminal.PromptOptions{RunningInAutomation: u.RunningInAutomation}
+}
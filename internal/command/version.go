@@ -0,0 +1,167 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	viewsjson "github.com/IkezawaYuki/lucky-strike/internal/command/views/json"
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+type VersionCommand struct {
+	Meta
+
+	Version           string
+	VersionPrerelease string
+	Platform          getproviders.Platform
+	CheckFunc         VersionCheckFunc
+}
+
+type VersionOutput struct {
+	Version            string            `json:"terraform_version"`
+	Platform           string            `json:"platform"`
+	GoRuntime          string            `json:"go_runtime"`
+	ProviderSelections map[string]string `json:"provider_selections"`
+	Outdated           bool              `json:"terraform_outdated"`
+	Latest             string            `json:"terraform_latest,omitempty"`
+}
+
+type VersionCheckFunc func(ctx context.Context) (VersionCheckInfo, error)
+
+type VersionCheckInfo struct {
+	Outdated bool
+	Latest   string
+}
+
+func (c *VersionCommand) Help() string {
+	helpText := `
+Usage: terraform version [options]
+
+  Displays the version of Terraform, the platform it is running on and the
+  providers selected in the dependency lock file of the current directory.
+
+Options:
+
+  -check      Compare the current version against the latest release
+              listed in the release index. The index URL can be set with
+              the TF_RELEASE_INDEX_URL environment variable. If the index
+              can't be read, the version is still shown but the command
+              exits with status 1.
+
+  -json       Output the version information as a JSON object.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VersionCommand) Run(args []string) int {
+	var jsonOutput, check bool
+	cmdFlags := c.Meta.defaultFlagSet("version")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.BoolVar(&check, "check", false, "check")
+	cmdFlags.Bool("v", true, "version")
+	cmdFlags.Bool("version", true, "version")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	versionString := c.Version
+	if c.VersionPrerelease != "" {
+		versionString = c.Version + "-" + c.VersionPrerelease
+	}
+
+	// A broken lock file only means that the provider selections can't
+	// all be shown, so the version is still shown along with the problems.
+	selections := make(map[string]string)
+	locks, lockDiags := c.lockedDependencies()
+	for providerAddr, lock := range locks.AllProviders() {
+		selections[providerAddr.String()] = lock.Version().String()
+	}
+
+	var info VersionCheckInfo
+	var checkErr error
+	if check && c.CheckFunc != nil {
+		info, checkErr = c.CheckFunc(c.callerContext())
+	}
+
+	// The version message must be the first in a JSON stream, so any error
+	// from the check is only reported after it.
+	if ui, ok := c.Ui.(*JSONUi); ok {
+		ui.Version(viewsjson.Version{
+			Version:            versionString,
+			Platform:           c.Platform.String(),
+			GoRuntime:          runtime.Version(),
+			ProviderSelections: selections,
+			Outdated:           info.Outdated,
+			Latest:             info.Latest,
+		})
+		c.showDiagnostics(lockDiags)
+		return c.reportCheckError(checkErr)
+	}
+
+	c.showDiagnostics(lockDiags)
+	status := c.reportCheckError(checkErr)
+
+	if jsonOutput {
+		output := VersionOutput{
+			Version:            versionString,
+			Platform:           c.Platform.String(),
+			GoRuntime:          runtime.Version(),
+			ProviderSelections: selections,
+			Outdated:           info.Outdated,
+			Latest:             info.Latest,
+		}
+
+		jsonOutput, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error marshalling JSON: %s", err))
+			return 1
+		}
+		fmt.Fprintln(c.Streams.Stdout.File, string(jsonOutput))
+		return status
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Terraform v%s\non %s\nbuilt with %s\n", versionString, c.Platform, runtime.Version())
+
+	var providerVersions []string
+	for providerAddr, version := range selections {
+		if version == "0.0.0" {
+			providerVersions = append(providerVersions, fmt.Sprintf("+ provider %s (unversioned)", providerAddr))
+		} else {
+			providerVersions = append(providerVersions, fmt.Sprintf("+ provider %s v%s", providerAddr, version))
+		}
+	}
+	sort.Strings(providerVersions)
+	for _, str := range providerVersions {
+		fmt.Fprintln(&out, str)
+	}
+
+	if info.Outdated {
+		fmt.Fprintf(&out,
+			"\nYour version of Terraform is out of date! The latest version\nis %s.\n",
+			info.Latest)
+	}
+
+	c.page(out.String())
+
+	return status
+}
+
+// reportCheckError shows an error from the -check option, if any, and
+// returns the exit status the command should finish with.
+func (c *VersionCommand) reportCheckError(err error) int {
+	if err == nil {
+		return 0
+	}
+	c.Ui.Error(fmt.Sprintf("Error checking latest version: %s", err))
+	return 1
+}
+
+func (c *VersionCommand) Synopsis() string {
+	return "Show the current Terraform version"
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"github.com/IkezawaYuki/lucky-strike/internal/depsfile"
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
+	"github.com/IkezawaYuki/lucky-strike/internal/terminal"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// tempChdir changes into a new temporary directory for the rest of the
+// test, and returns a function that changes back and removes it.
+func tempChdir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+	}
+}
+
+func testVersionCommand(streams *terminal.Streams, check VersionCheckFunc) *VersionCommand {
+	return &VersionCommand{
+		Meta: Meta{
+			Streams: streams,
+			Ui:      NewStreamsUi(streams, false, false),
+			NoPager: true,
+		},
+		Version:           "0.14.8",
+		VersionPrerelease: "rc1",
+		Platform:          getproviders.Platform{OS: "linux", Arch: "amd64"},
+		CheckFunc:         check,
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	defer tempChdir(t)()
+	err := ioutil.WriteFile(depsfile.LockFilePath, []byte(`
+provider "registry.terraform.io/hashicorp/aws" {
+  version = "3.30.0"
+}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streams, close := terminal.StreamsForTesting(t, terminal.TestTerminal{})
+	c := testVersionCommand(streams, nil)
+	if code := c.Run(nil); code != 0 {
+		t.Errorf("wrong exit status %d", code)
+	}
+
+	out := close(t)
+	for _, want := range []string{
+		"Terraform v0.14.8-rc1\non linux_amd64\n",
+		"+ provider registry.terraform.io/hashicorp/aws v3.30.0\n",
+	} {
+		if !strings.Contains(out.Stdout(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.Stdout())
+		}
+	}
+	if out.Stderr() != "" {
+		t.Errorf("unexpected errors:\n%s", out.Stderr())
+	}
+}
+
+func TestVersionCommand_invalidLockFile(t *testing.T) {
+	defer tempChdir(t)()
+	if err := ioutil.WriteFile(depsfile.LockFilePath, []byte("provider {"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	streams, close := terminal.StreamsForTesting(t, terminal.TestTerminal{})
+	c := testVersionCommand(streams, nil)
+	if code := c.Run(nil); code != 0 {
+		t.Errorf("wrong exit status %d", code)
+	}
+
+	out := close(t)
+	if want := "Terraform v0.14.8-rc1\n"; !strings.Contains(out.Stdout(), want) {
+		t.Errorf("output is missing %q:\n%s", want, out.Stdout())
+	}
+	if !strings.Contains(out.Stderr(), "Error: ") {
+		t.Errorf("lock file errors not shown:\n%s", out.Stderr())
+	}
+}
+
+func TestVersionCommand_check(t *testing.T) {
+	tests := map[string]struct {
+		info       VersionCheckInfo
+		err        error
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		"up to date": {
+			info:     VersionCheckInfo{Latest: "0.14.8"},
+			wantCode: 0,
+		},
+		"outdated": {
+			info:       VersionCheckInfo{Outdated: true, Latest: "0.15.0"},
+			wantCode:   0,
+			wantStdout: "Your version of Terraform is out of date! The latest version\nis 0.15.0.\n",
+		},
+		"failed": {
+			err:        errors.New("index unavailable"),
+			wantCode:   1,
+			wantStderr: "Error checking latest version: index unavailable",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer tempChdir(t)()
+
+			streams, close := terminal.StreamsForTesting(t, terminal.TestTerminal{})
+			c := testVersionCommand(streams, func(context.Context) (VersionCheckInfo, error) {
+				return test.info, test.err
+			})
+			if code := c.Run([]string{"-check"}); code != test.wantCode {
+				t.Errorf("wrong exit status %d; want %d", code, test.wantCode)
+			}
+
+			out := close(t)
+			if !strings.HasPrefix(out.Stdout(), "Terraform v0.14.8-rc1\n") {
+				t.Errorf("version not shown:\n%s", out.Stdout())
+			}
+			if !strings.Contains(out.Stdout(), test.wantStdout) {
+				t.Errorf("output is missing %q:\n%s", test.wantStdout, out.Stdout())
+			}
+			if !strings.Contains(out.Stderr(), test.wantStderr) {
+				t.Errorf("errors are missing %q:\n%s", test.wantStderr, out.Stderr())
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package json
+
+import (
+	"github.com/IkezawaYuki/lucky-strike/internal/tfdiags"
+)
+
+const (
+	DiagnosticSeverityUnknown = "unknown"
+	DiagnosticSeverityError   = "error"
+	DiagnosticSeverityWarning = "warning"
+)
+
+type Diagnostic struct {
+	Severity string             `json:"severity"`
+	Summary  string             `json:"summary"`
+	Detail   string             `json:"detail"`
+	Range    *DiagnosticRange   `json:"range,omitempty"`
+	Snippet  *DiagnosticSnippet `json:"snippet,omitempty"`
+}
+
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+type DiagnosticRange struct {
+	Filename string `json:"filename"`
+	Start    Pos    `json:"start"`
+	End      Pos    `json:"end"`
+}
+
+type DiagnosticSnippet struct {
+	StartLine int    `json:"start_line"`
+	Code      string `json:"code"`
+
+	// HighlightStartOffset and HighlightEndOffset are byte offsets into
+	// Code covering the part of the snippet that the diagnostic refers to.
+	HighlightStartOffset int `json:"highlight_start_offset"`
+	HighlightEndOffset   int `json:"highlight_end_offset"`
+}
+
+func NewDiagnostic(diag tfdiags.Diagnostic, sources map[string][]byte) *Diagnostic {
+	var sev string
+	switch diag.Severity {
+	case tfdiags.Error:
+		sev = DiagnosticSeverityError
+	case tfdiags.Warning:
+		sev = DiagnosticSeverityWarning
+	default:
+		sev = DiagnosticSeverityUnknown
+	}
+
+	ret := &Diagnostic{
+		Severity: sev,
+		Summary:  diag.Summary,
+		Detail:   diag.Detail,
+	}
+
+	subject := diag.Subject
+	if subject == nil {
+		return ret
+	}
+
+	ret.Range = &DiagnosticRange{
+		Filename: subject.Filename,
+		Start: Pos{
+			Line:   subject.Start.Line,
+			Column: subject.Start.Column,
+			Byte:   subject.Start.Byte,
+		},
+		End: Pos{
+			Line:   subject.End.Line,
+			Column: subject.End.Column,
+			Byte:   subject.End.Byte,
+		},
+	}
+
+	src, ok := sources[subject.Filename]
+	if !ok {
+		return ret
+	}
+	lines := subject.SourceLines(src)
+	if len(lines) == 0 {
+		return ret
+	}
+
+	snippet := &DiagnosticSnippet{
+		StartLine: lines[0].Number,
+	}
+	offset := 0
+	for i, line := range lines {
+		if i > 0 {
+			snippet.Code += "\n"
+			offset++
+		}
+		if i == 0 {
+			snippet.HighlightStartOffset = offset + line.HighlightStart
+		}
+		snippet.HighlightEndOffset = offset + line.HighlightEnd
+		snippet.Code += line.Text
+		offset += len(line.Text)
+	}
+	ret.Snippet = snippet
+
+	return ret
+}
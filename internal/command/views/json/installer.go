@@ -0,0 +1,19 @@
+package json
+
+type InstallerEventType string
+
+const (
+	InstallerQueryVersions      InstallerEventType = "query_versions"
+	InstallerFetchPackageBegin  InstallerEventType = "fetch_package_begin"
+	InstallerFetchPackageEnd    InstallerEventType = "fetch_package_end"
+	InstallerFetchPackageFailed InstallerEventType = "fetch_package_failed"
+	InstallerVerifyPackage      InstallerEventType = "verify_package"
+)
+
+type InstallerEvent struct {
+	Event    InstallerEventType `json:"event"`
+	Provider string             `json:"provider"`
+	Version  string             `json:"version,omitempty"`
+	Location string             `json:"location,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
@@ -0,0 +1,10 @@
+package json
+
+type MessageType string
+
+const (
+	MessageVersion        MessageType = "version"
+	MessageLog            MessageType = "log"
+	MessageDiagnostic     MessageType = "diagnostic"
+	MessageInstallerEvent MessageType = "installer_event"
+)
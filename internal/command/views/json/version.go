@@ -0,0 +1,10 @@
+package json
+
+type Version struct {
+	Version            string            `json:"version"`
+	Platform           string            `json:"platform,omitempty"`
+	GoRuntime          string            `json:"go_runtime,omitempty"`
+	ProviderSelections map[string]string `json:"provider_selections,omitempty"`
+	Outdated           bool              `json:"outdated,omitempty"`
+	Latest             string            `json:"latest,omitempty"`
+}
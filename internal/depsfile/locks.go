@@ -0,0 +1,41 @@
+package depsfile
+
+import (
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
+	"github.com/hashicorp/terraform/addrs"
+)
+
+type Locks struct {
+	providers map[addrs.Provider]*ProviderLock
+}
+
+func NewLocks() *Locks {
+	return &Locks{
+		providers: make(map[addrs.Provider]*ProviderLock),
+	}
+}
+
+func (l *Locks) Provider(addr addrs.Provider) *ProviderLock {
+	return l.providers[addr]
+}
+
+func (l *Locks) AllProviders() map[addrs.Provider]*ProviderLock {
+	ret := make(map[addrs.Provider]*ProviderLock, len(l.providers))
+	for addr, lock := range l.providers {
+		ret[addr] = lock
+	}
+	return ret
+}
+
+type ProviderLock struct {
+	addr    addrs.Provider
+	version getproviders.Version
+}
+
+func (l *ProviderLock) Provider() addrs.Provider {
+	return l.addr
+}
+
+func (l *ProviderLock) Version() getproviders.Version {
+	return l.version
+}
@@ -0,0 +1,105 @@
+package depsfile
+
+import (
+	"fmt"
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
+	"github.com/IkezawaYuki/lucky-strike/internal/tfdiags"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/terraform/addrs"
+)
+
+func LoadLocksFromFile(filename string) (*Locks, tfdiags.Diagnostics) {
+	ret := NewLocks()
+
+	var diags tfdiags.Diagnostics
+
+	parser := hclparse.NewParser()
+	f, hclDiags := parser.ParseHCLFile(filename)
+	diags = diags.Append(hclDiags)
+	if f == nil {
+		return ret, diags
+	}
+
+	content, hclDiags := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "provider",
+				LabelNames: []string{"source_addr"},
+			},
+		},
+	})
+	diags = diags.Append(hclDiags)
+
+	for _, block := range content.Blocks {
+		lock, moreDiags := decodeProviderLockFromHCL(block)
+		diags = diags.Append(moreDiags)
+		if lock == nil {
+			continue
+		}
+		if _, exists := ret.providers[lock.addr]; exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate provider lock",
+				Detail:   fmt.Sprintf("This lockfile already declared a lock for provider %s.", lock.addr),
+				Subject:  block.TypeRange.Ptr(),
+			})
+			continue
+		}
+		ret.providers[lock.addr] = lock
+	}
+
+	return ret, diags
+}
+
+func decodeProviderLockFromHCL(block *hcl.Block) (*ProviderLock, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	rawAddr := block.Labels[0]
+	addr, moreDiags := addrs.ParseProviderSourceString(rawAddr)
+	if moreDiags.HasErrors() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid provider source address",
+			Detail:   "The provider source address for a provider lock must be a valid, fully-qualified address of the form \"hostname/namespace/type\".",
+			Subject:  block.LabelRanges[0].Ptr(),
+		})
+		return nil, diags
+	}
+
+	content, _, hclDiags := block.Body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "version", Required: true},
+		},
+	})
+	diags = diags.Append(hclDiags)
+
+	attr, ok := content.Attributes["version"]
+	if !ok {
+		return nil, diags
+	}
+
+	var raw string
+	hclDiags = gohcl.DecodeExpression(attr.Expr, nil, &raw)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+
+	version, err := getproviders.ParseVersion(raw)
+	if err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid provider version number",
+			Detail:   fmt.Sprintf("The selected version number for provider %s is invalid: %s.", addr, err),
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+		return nil, diags
+	}
+
+	return &ProviderLock{
+		addr:    addr,
+		version: version,
+	}, diags
+}
@@ -0,0 +1,132 @@
+package depsfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadLocksFromFile(t *testing.T) {
+	tests := map[string]struct {
+		src       string
+		want      map[string]string
+		wantDiags []string
+	}{
+		"empty": {
+			src:  "",
+			want: map[string]string{},
+		},
+		"valid": {
+			src: `
+provider "registry.terraform.io/hashicorp/aws" {
+  version = "3.30.0"
+}
+provider "example.com/acme/widget" {
+  version = "0.1.0-beta.1"
+}
+`,
+			want: map[string]string{
+				"registry.terraform.io/hashicorp/aws": "3.30.0",
+				"example.com/acme/widget":             "0.1.0-beta.1",
+			},
+		},
+		"duplicate provider": {
+			src: `
+provider "registry.terraform.io/hashicorp/aws" {
+  version = "3.30.0"
+}
+provider "registry.terraform.io/hashicorp/aws" {
+  version = "3.31.0"
+}
+`,
+			want: map[string]string{
+				"registry.terraform.io/hashicorp/aws": "3.30.0",
+			},
+			wantDiags: []string{"Duplicate provider lock"},
+		},
+		"invalid source address": {
+			src: `
+provider "not a valid address" {
+  version = "1.0.0"
+}
+`,
+			want:      map[string]string{},
+			wantDiags: []string{"Invalid provider source address"},
+		},
+		"invalid version": {
+			src: `
+provider "registry.terraform.io/hashicorp/aws" {
+  version = "latest"
+}
+`,
+			want:      map[string]string{},
+			wantDiags: []string{"Invalid provider version number"},
+		},
+		"missing version": {
+			src: `
+provider "registry.terraform.io/hashicorp/aws" {
+}
+`,
+			want:      map[string]string{},
+			wantDiags: []string{"Missing required argument"},
+		},
+		"syntax error": {
+			// Whatever can be recovered from a broken file is still
+			// returned alongside the errors.
+			src: "provider \"registry.terraform.io/hashicorp/aws\" {\n  version = \"3.30.0\"\n",
+			want: map[string]string{
+				"registry.terraform.io/hashicorp/aws": "3.30.0",
+			},
+			wantDiags: []string{"Argument or block definition required"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "depsfile")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			filename := filepath.Join(dir, LockFilePath)
+			if err := ioutil.WriteFile(filename, []byte(test.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			locks, diags := LoadLocksFromFile(filename)
+
+			got := make(map[string]string)
+			for addr, lock := range locks.AllProviders() {
+				got[addr.String()] = lock.Version().String()
+			}
+			if len(got) != len(test.want) {
+				t.Errorf("wrong locks %#v; want %#v", got, test.want)
+			}
+			for addr, version := range test.want {
+				if got[addr] != version {
+					t.Errorf("wrong version for %s %q; want %q", addr, got[addr], version)
+				}
+			}
+
+			var gotDiags []string
+			for _, diag := range diags {
+				gotDiags = append(gotDiags, diag.Summary)
+			}
+			if strings.Join(gotDiags, "\n") != strings.Join(test.wantDiags, "\n") {
+				t.Errorf("wrong diagnostics %q; want %q", gotDiags, test.wantDiags)
+			}
+		})
+	}
+}
+
+func TestLoadLocksFromFile_missing(t *testing.T) {
+	locks, diags := LoadLocksFromFile(filepath.Join("testdata", "does-not-exist.hcl"))
+	if !diags.HasErrors() {
+		t.Error("no error for a missing file")
+	}
+	if got := len(locks.AllProviders()); got != 0 {
+		t.Errorf("got %d locks for a missing file", got)
+	}
+}
@@ -0,0 +1,3 @@
+package depsfile
+
+const LockFilePath = ".terraform.lock.hcl"
@@ -2,10 +2,12 @@ package getproviders
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"github.com/IkezawaYuki/lucky-strike/internal/tracing"
 )
 
 type packageAuthenticationResult int
@@ -75,11 +77,22 @@ func PackageAuthenticationAll(check ...PackageAuthentication) PackageAuthenticat
 }
 
 func (checks packageAuthenticationAll) AuthenticatePackage(localLocation PackageLocation) (*PackageAuthenticationResult, error) {
+	ctx, span := tracing.Start(context.Background(), "getproviders.AuthenticatePackage",
+		"location", localLocation.String(),
+	)
+	defer span.End()
+
 	var authResult *PackageAuthenticationResult
 	for _, check := range checks {
+		_, checkSpan := tracing.Start(ctx, "getproviders.AuthenticatePackage.check",
+			"check", fmt.Sprintf("%T", check),
+		)
 		var err error
 		authResult, err = check.AuthenticatePackage(localLocation)
+		checkSpan.RecordError(err)
+		checkSpan.End()
 		if err != nil {
+			span.RecordError(err)
 			return authResult, err
 		}
 	}
@@ -0,0 +1,42 @@
+package getproviders
+
+import (
+	"context"
+	"github.com/IkezawaYuki/lucky-strike/internal/tracing"
+	"github.com/hashicorp/terraform/addrs"
+)
+
+// TracingSource wraps another Source so that each of its requests is
+// recorded as a span when tracing is enabled.
+type TracingSource struct {
+	Source
+}
+
+func NewTracingSource(wrapped Source) *TracingSource {
+	return &TracingSource{Source: wrapped}
+}
+
+func (s *TracingSource) AvailableVersions(ctx context.Context, provider addrs.Provider) (VersionList, Warnings, error) {
+	ctx, span := tracing.Start(ctx, "getproviders.AvailableVersions",
+		"provider", provider.String(),
+	)
+	defer span.End()
+
+	versions, warnings, err := s.Source.AvailableVersions(ctx, provider)
+	span.SetAttributes("versions", len(versions), "warnings", len(warnings))
+	span.RecordError(err)
+	return versions, warnings, err
+}
+
+func (s *TracingSource) PackageMeta(ctx context.Context, provider addrs.Provider, version Version, target Platform) (PackageMeta, error) {
+	ctx, span := tracing.Start(ctx, "getproviders.PackageMeta",
+		"provider", provider.String(),
+		"version", version.String(),
+		"platform", target.String(),
+	)
+	defer span.End()
+
+	meta, err := s.Source.PackageMeta(ctx, provider, version, target)
+	span.RecordError(err)
+	return meta, err
+}
@@ -0,0 +1,166 @@
+package logging
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	envCrashDir = "TF_CRASH_DIR"
+
+	defaultCrashDir = "."
+)
+
+// CrashInfo is the information about the running program that the caller
+// of PanicHandler knows and the logging package does not.
+type CrashInfo struct {
+	Version  string
+	Platform string
+	Args     []string
+}
+
+type crashMetadata struct {
+	Version   string    `json:"version"`
+	GoRuntime string    `json:"go_runtime"`
+	Platform  string    `json:"platform"`
+	Args      []string  `json:"args"`
+	EnvVars   []string  `json:"env_vars"`
+	Time      time.Time `json:"time"`
+}
+
+type crashFile struct {
+	name    string
+	content []byte
+}
+
+func writeCrashBundle(tmpLogPath, pluginPanicLogPath string, info CrashInfo, panicText string) (string, error) {
+	dir := os.Getenv(envCrashDir)
+	if dir == "" {
+		dir = defaultCrashDir
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile(dir, "crash.*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	panicText = redactor.redact(panicText)
+
+	var crashLog bytes.Buffer
+	if tmpLog, err := os.Open(tmpLogPath); err == nil {
+		err = copyRedacted(&crashLog, tmpLog)
+		tmpLog.Close()
+		if err != nil {
+			fmt.Fprintf(&crashLog, "Failed to read log file %q: %s\n", tmpLogPath, err)
+		}
+	} else {
+		fmt.Fprintf(&crashLog, "Failed to open log file %q: %s\n", tmpLogPath, err)
+	}
+	crashLog.WriteString("\n" + panicText)
+
+	args := make([]string, len(info.Args))
+	for i, arg := range info.Args {
+		args[i] = redactor.redact(arg)
+	}
+	metadata, err := json.MarshalIndent(crashMetadata{
+		Version:   info.Version,
+		GoRuntime: runtime.Version(),
+		Platform:  info.Platform,
+		Args:      args,
+		EnvVars:   environmentNames(),
+		Time:      time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	files := []crashFile{
+		{"crash.log", crashLog.Bytes()},
+		{"panic.txt", []byte(panicText)},
+		{"metadata.json", metadata},
+	}
+	if pluginPanics := recoverPluginPanics(pluginPanicLogPath); len(pluginPanics) > 0 {
+		files = append(files, crashFile{"plugin_panics.txt", []byte(redactor.redact(strings.Join(pluginPanics, "\n")))})
+	}
+
+	zw := zip.NewWriter(f)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return "", err
+		}
+		if _, err := w.Write(file.content); err != nil {
+			return "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	// The bundle may be anywhere if TF_CRASH_DIR is set, so the path is
+	// reported in full rather than relative to the working directory.
+	path, err := filepath.Abs(f.Name())
+	if err != nil {
+		return f.Name(), nil
+	}
+	return path, nil
+}
+
+func environmentNames() []string {
+	var names []string
+	for _, kv := range os.Environ() {
+		if eq := strings.Index(kv, "="); eq > 0 {
+			names = append(names, kv[:eq])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// recoverPluginPanics combines the plugin panics recorded in this process
+// with those a crashed child process wrote to pluginPanicLogPath.
+func recoverPluginPanics(pluginPanicLogPath string) []string {
+	ret := PluginPanics()
+	if pluginPanicLogPath == "" {
+		return ret
+	}
+
+	f, err := os.Open(pluginPanicLogPath)
+	if err != nil {
+		return ret
+	}
+	defer f.Close()
+
+	recovered := newPanicRecorder(panics.maxLines)
+	recorders := make(map[string]func(string))
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.SplitN(sc.Text(), "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name, line := parts[0], parts[1]
+		record, ok := recorders[name]
+		if !ok {
+			record = recovered.registerPlugin(name)
+			recorders[name] = record
+		}
+		record(line)
+	}
+
+	return append(ret, recovered.allPanics()...)
+}
@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteCrashBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	crashDir := filepath.Join(dir, "crashes")
+	defer setenv(envCrashDir, crashDir)()
+	defer setenv("TF_TEST_CRASH_VAR", "env-value-not-in-bundle")()
+
+	defer func(old *panicRecorder) { panics = old }(panics)
+	panics = newPanicRecorder(100)
+	defer func(secrets []string) {
+		redactor.Lock()
+		redactor.secrets = secrets
+		redactor.Unlock()
+	}(redactor.secrets)
+	RegisterSecret("s3cr3t-token")
+
+	logPath := filepath.Join(dir, "log")
+	err = ioutil.WriteFile(logPath, []byte("[DEBUG] using token s3cr3t-token\n[INFO] about to crash\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pluginPanicPath := filepath.Join(dir, "plugin-panics")
+	err = ioutil.WriteFile(pluginPanicPath, []byte("aws\tpanic: provider failed\naws\t\naws\tgoroutine 1 [running]:\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := writeCrashBundle(logPath, pluginPanicPath, CrashInfo{
+		Version:  "0.14.8",
+		Platform: "linux_amd64",
+		Args:     []string{"terraform", "apply", "-var=token=s3cr3t-token"},
+	}, "panic: boom\n\ngoroutine 1 [running]:\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !filepath.IsAbs(path) || filepath.Dir(path) != crashDir {
+		t.Errorf("bundle written to %q; want an absolute path in %q", path, crashDir)
+	}
+
+	files := readZip(t, path)
+
+	if got, want := files["crash.log"], "[DEBUG] using token [REDACTED]\n[INFO] about to crash\n\npanic: boom\n\ngoroutine 1 [running]:\n"; got != want {
+		t.Errorf("wrong crash.log\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := files["panic.txt"], "panic: boom\n\ngoroutine 1 [running]:\n"; got != want {
+		t.Errorf("wrong panic.txt\ngot:  %q\nwant: %q", got, want)
+	}
+	if got := files["plugin_panics.txt"]; !strings.Contains(got, "Stack trace from the aws plugin:\npanic: provider failed\n\ngoroutine 1 [running]:\n") {
+		t.Errorf("plugin panic not recovered:\n%s", got)
+	}
+
+	var metadata crashMetadata
+	if err := json.Unmarshal([]byte(files["metadata.json"]), &metadata); err != nil {
+		t.Fatalf("invalid metadata.json: %s", err)
+	}
+	if metadata.Version != "0.14.8" || metadata.Platform != "linux_amd64" {
+		t.Errorf("wrong version details in %#v", metadata)
+	}
+	if got, want := strings.Join(metadata.Args, " "), "terraform apply -var=token=[REDACTED]"; got != want {
+		t.Errorf("wrong args %q; want %q", got, want)
+	}
+	found := false
+	for _, name := range metadata.EnvVars {
+		if name == "TF_TEST_CRASH_VAR" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("environment variable name missing from %q", metadata.EnvVars)
+	}
+
+	for name, content := range files {
+		for _, secret := range []string{"s3cr3t-token", "env-value-not-in-bundle"} {
+			if strings.Contains(content, secret) {
+				t.Errorf("%s contains %q", name, secret)
+			}
+		}
+	}
+}
+
+func TestWriteCrashBundle_missingLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer setenv(envCrashDir, dir)()
+
+	defer func(old *panicRecorder) { panics = old }(panics)
+	panics = newPanicRecorder(100)
+
+	path, err := writeCrashBundle(filepath.Join(dir, "missing"), "", CrashInfo{}, "panic: boom\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	files := readZip(t, path)
+	if got := files["crash.log"]; !strings.HasPrefix(got, "Failed to open log file") || !strings.HasSuffix(got, "panic: boom\n") {
+		t.Errorf("wrong crash.log:\n%s", got)
+	}
+	if _, ok := files["plugin_panics.txt"]; ok {
+		t.Error("plugin_panics.txt written although no plugin panicked")
+	}
+}
+
+func readZip(t *testing.T, path string) map[string]string {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("failed to open bundle: %s", err)
+	}
+	defer zr.Close()
+
+	ret := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret[f.Name] = string(content)
+	}
+	return ret
+}
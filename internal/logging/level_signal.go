@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"github.com/hashicorp/go-hclog"
+	"sync"
+)
+
+var (
+	levelMu      sync.Mutex
+	currentLevel hclog.Level
+)
+
+func CurrentLogLevel() hclog.Level {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	return currentLevel
+}
+
+// shiftLogLevel moves the global logger's level by delta steps, where a
+// negative delta is more verbose, staying within TRACE and OFF.
+func shiftLogLevel(delta int) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	old := currentLevel
+	next := old + hclog.Level(delta)
+	if next < hclog.Trace {
+		next = hclog.Trace
+	}
+	if next > hclog.Off {
+		next = hclog.Off
+	}
+	if next == old {
+		return
+	}
+
+	// The transition is logged at whichever of the two levels is more
+	// verbose, while that level is in effect, so it is always visible.
+	if next < old {
+		logger.SetLevel(next)
+		logTransition(next, old, next)
+	} else {
+		logTransition(old, old, next)
+		logger.SetLevel(next)
+	}
+	currentLevel = next
+}
+
+func logTransition(at, old, next hclog.Level) {
+	if at > hclog.Error {
+		return
+	}
+	logger.Log(at, "log level changed by signal", "from", old.String(), "to", next.String())
+}
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"bytes"
+	"github.com/hashicorp/go-hclog"
+	"strings"
+	"testing"
+)
+
+func TestShiftLogLevel(t *testing.T) {
+	defer func(old hclog.Level) {
+		currentLevel = old
+		logger.SetLevel(old)
+	}(CurrentLogLevel())
+
+	tests := map[string]struct {
+		start hclog.Level
+		delta int
+		want  hclog.Level
+		// logged is the level the transition is logged at, or NoLevel
+		// if nothing should be logged.
+		logged hclog.Level
+	}{
+		"more verbose": {
+			start:  hclog.Info,
+			delta:  -1,
+			want:   hclog.Debug,
+			logged: hclog.Debug,
+		},
+		"less verbose": {
+			start:  hclog.Debug,
+			delta:  1,
+			want:   hclog.Info,
+			logged: hclog.Debug,
+		},
+		"clamped at trace": {
+			start:  hclog.Info,
+			delta:  -5,
+			want:   hclog.Trace,
+			logged: hclog.Trace,
+		},
+		"clamped at off": {
+			start:  hclog.Warn,
+			delta:  5,
+			want:   hclog.Off,
+			logged: hclog.Warn,
+		},
+		"already trace": {
+			start:  hclog.Trace,
+			delta:  -1,
+			want:   hclog.Trace,
+			logged: hclog.NoLevel,
+		},
+		"already off": {
+			start:  hclog.Off,
+			delta:  1,
+			want:   hclog.Off,
+			logged: hclog.NoLevel,
+		},
+		"from off": {
+			start:  hclog.Off,
+			delta:  -1,
+			want:   hclog.Error,
+			logged: hclog.Error,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			currentLevel = test.start
+			logger.SetLevel(test.start)
+
+			var buf bytes.Buffer
+			sink := AddSink(&buf, SinkOptions{Level: hclog.Trace})
+			shiftLogLevel(test.delta)
+			RemoveSink(sink)
+
+			if got := CurrentLogLevel(); got != test.want {
+				t.Errorf("wrong level %s; want %s", got, test.want)
+			}
+
+			got := buf.String()
+			if test.logged == hclog.NoLevel {
+				if got != "" {
+					t.Errorf("unexpected log output: %q", got)
+				}
+				return
+			}
+			level := "[" + strings.ToUpper(test.logged.String()) + "]"
+			msg := "log level changed by signal: from=" + test.start.String() + " to=" + test.want.String()
+			if !strings.Contains(got, level) || !strings.Contains(got, msg) {
+				t.Errorf("wrong log output\ngot:  %q\nwant: %s %s", got, level, msg)
+			}
+		})
+	}
+}
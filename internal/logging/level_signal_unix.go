@@ -0,0 +1,38 @@
+//go:build !windows
+// +build !windows
+
+package logging
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ListenForLevelSignals makes the global logger more verbose on SIGUSR1
+// and less verbose on SIGUSR2 until the returned function is called.
+func ListenForLevelSignals() func() {
+	sigCh := make(chan os.Signal, 1)
+	doneCh := make(chan struct{})
+	signal.Notify(sigCh, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				if sig == syscall.SIGUSR1 {
+					shiftLogLevel(-1)
+				} else {
+					shiftLogLevel(1)
+				}
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(doneCh)
+	}
+}
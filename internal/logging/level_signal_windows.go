@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package logging
+
+// ListenForLevelSignals does nothing on Windows, which has no SIGUSR1 or
+// SIGUSR2.
+func ListenForLevelSignals() func() {
+	return func() {}
+}
@@ -7,28 +7,28 @@ import (
 	"log"
 	"os"
 	"strings"
-	"syscall"
 )
 
 const (
-	envLog     = "TF_LOG"
-	envLogFile = "TF_LOG_PATH"
+	envLog       = "TF_LOG"
+	envLogFile   = "TF_LOG_PATH"
+	envLogFormat = "TF_LOG_FORMAT"
 
-	envLogCore = "TF_LOG_CORE"
+	envLogCore      = "TF_LOG_CORE"
+	envLogProvider  = "TF_LOG_PROVIDER"
+	envLogInstaller = "TF_LOG_INSTALLER"
 )
 
 var (
 	ValidLevels = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "OFF"}
 	logger      hclog.Logger
 	logWriter   io.Writer
-	panics      = &panicRecorder{
-		panics:   make(map[string][]string),
-		maxLines: 100,
-	}
+	panics      = newPanicRecorder(100)
 )
 
 func init() {
-	logger = newHCLogger("")
+	currentLevel = globalLogLevel()
+	logger = newHCLogger("", currentLevel)
 	logWriter = logger.StandardWriter(&hclog.StandardLoggerOptions{InferLevels: true})
 	log.SetFlags(0)
 	log.SetPrefix("")
@@ -36,27 +36,57 @@ func init() {
 }
 
 func RegisterSink(f *os.File) {
-	l, ok := logger.(hclog.InterceptLogger)
-	if !ok {
-		panic("global logger is not an InterceptLogger")
-	}
-
 	if f == nil {
 		return
 	}
 
-	l.RegisterSink(hclog.NewSinkAdapter(&hclog.LoggerOptions{
-		Level:  hclog.Trace,
-		Output: f,
-	}))
+	AddSink(f, SinkOptions{
+		Level:      hclog.Trace,
+		JSONFormat: jsonLogFormat(),
+	})
 }
 
-func newHCLogger(name string) hclog.Logger {
+func NewLogger(name string) hclog.Logger {
+	if name == "" {
+		panic("logger name required")
+	}
+	return logger.Named(name)
+}
+
+// NewProviderLogger returns the logger to hand to the plugin client. The
+// client derives a named logger from it for each plugin process it starts,
+// and lines that plugin writes to stderr after a "panic:" or "fatal error:"
+// line are recorded for PluginPanics.
+func NewProviderLogger(prefix string) hclog.Logger {
+	return &logPanicWrapper{
+		Logger: newSubsystemLogger(prefix+"provider", envLogProvider),
+	}
+}
+
+// NewPluginLogger returns the logger for a single plugin process, for
+// callers that start plugin processes themselves rather than via a client
+// that calls Named.
+func NewPluginLogger(prefix, pluginName string) hclog.Logger {
+	return NewProviderLogger(prefix).Named(pluginName)
+}
+
+func NewInstallerLogger() hclog.Logger {
+	return newSubsystemLogger("installer", envLogInstaller)
+}
+
+func newSubsystemLogger(name, envVar string) hclog.Logger {
+	level := subsystemLogLevel(envVar)
+	l := logger.Named(name)
+	l.SetLevel(level)
+	logger.Debug("created subsystem logger", "name", name, "level", level)
+	return l
+}
+
+func newHCLogger(name string, logLevel hclog.Level) hclog.Logger {
 	logOutput := io.Writer(os.Stderr)
-	logLevel := globalLogLevel()
 
 	if logPath := os.Getenv(envLogFile); logPath != "" {
-		f, err := os.OpenFile(logPath, syscall.O_CREAT|syscall.O_RDWR|syscall.O_APPEND, 0666)
+		f, err := openLogFile(logPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error opening log file: %v\n", err)
 		} else {
@@ -67,19 +97,35 @@ func newHCLogger(name string) hclog.Logger {
 	return hclog.NewInterceptLogger(&hclog.LoggerOptions{
 		Name:              name,
 		Level:             logLevel,
-		Output:            logOutput,
+		Output:            newRedactingWriter(logOutput),
 		IndependentLevels: true,
+		JSONFormat:        jsonLogFormat(),
 	})
 }
 
 func globalLogLevel() hclog.Level {
-	envLevel := strings.ToUpper(os.Getenv(envLog))
+	return subsystemLogLevel(envLogCore)
+}
+
+// subsystemLogLevel returns the level set by the given subsystem-specific
+// environment variable, falling back to TF_LOG when it is unset.
+func subsystemLogLevel(envVar string) hclog.Level {
+	envLevel := strings.ToUpper(os.Getenv(envVar))
 	if envLevel == "" {
-		envLevel = strings.ToUpper(os.Getenv(envLogCore))
+		envLevel = strings.ToUpper(os.Getenv(envLog))
 	}
 	return parseLogLevel(envLevel)
 }
 
+// jsonLogFormat reports whether logs should be written as JSON lines,
+// either because TF_LOG=JSON or because TF_LOG_FORMAT=json.
+func jsonLogFormat() bool {
+	if strings.ToUpper(os.Getenv(envLog)) == "JSON" {
+		return true
+	}
+	return strings.ToUpper(os.Getenv(envLogFormat)) == "JSON"
+}
+
 func parseLogLevel(envLevel string) hclog.Level {
 	if envLevel == "" {
 		return hclog.Off
@@ -88,7 +134,7 @@ func parseLogLevel(envLevel string) hclog.Level {
 	logLevel := hclog.Trace
 	if isValidLogLevel(envLevel) {
 		logLevel = hclog.LevelFromString(envLevel)
-	} else {
+	} else if envLevel != "JSON" {
 		fmt.Fprintf(os.Stderr, "[WARN] Invalid log level: %q. Defaulting to level: TRACE. Valid levels are: %+v",
 			envLevel, ValidLevels)
 	}
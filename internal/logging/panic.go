@@ -5,8 +5,8 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/mitchellh/panicwrap"
 	"io"
-	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -14,44 +14,29 @@ import (
 const panicOutput = `
 !!!!!!!!!!!!!!!!!!!!!!!!!!! TERRAFORM CRASH !!!!!!!!!!!!!!!!!!!!!!!!!!!!
 Terraform crashed! This is always indicative of a bug within Terraform.
-A crash log has been placed at %[1]q relative to your current
-working directory. It would be immensely helpful if you could please
-report the crash with Terraform[1] so that we can fix this.
-When reporting bugs, please include your terraform version. That
-information is available on the first line of crash.log. You can also
-get it by running 'terraform --version' on the command line.
-SECURITY WARNING: the %[1]q file that was created may contain 
-sensitive information that must be redacted before it is safe to share 
-on the issue tracker.
+A crash bundle has been placed at %[1]q.
+It would be immensely helpful if you could please report the crash with
+Terraform[1] so that we can fix this.
+The bundle contains the log, the panic stack trace, the Terraform
+version and platform, the command line arguments, the names (but not
+the values) of the environment variables and any plugin crashes.
+SECURITY WARNING: known credentials are masked in the %[1]q file, but
+it may still contain sensitive information that must be redacted before
+it is safe to share on the issue tracker.
 [1]: https://github.com/hashicorp/terraform/issues
 !!!!!!!!!!!!!!!!!!!!!!!!!!! TERRAFORM CRASH !!!!!!!!!!!!!!!!!!!!!!!!!!!!
 `
 
-func PanicHandler(tmpLogPath string) panicwrap.HandlerFunc {
+func PanicHandler(tmpLogPath, pluginPanicLogPath string, info CrashInfo) panicwrap.HandlerFunc {
 	return func(m string) {
-		f, err := ioutil.TempFile(".", "crash.*.log")
+		path, err := writeCrashBundle(tmpLogPath, pluginPanicLogPath, info, m)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to create crash log file: %s", err)
+			fmt.Fprintf(os.Stderr, "Failed to create crash bundle: %s\n", err)
 			return
 		}
-		defer f.Close()
-
-		tmpLog, err := os.Open(tmpLogPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to open log file %q: %v\n", tmpLogPath, err)
-			return
-		}
-		defer tmpLog.Close()
-
-		if _, err = io.Copy(f, tmpLog); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to open log file %q: %v\n", tmpLogPath, err)
-			return
-		}
-
-		f.WriteString("\n" + m)
 
 		fmt.Printf("\n\n")
-		fmt.Printf(panicOutput, f.Name())
+		fmt.Printf(panicOutput, path)
 	}
 }
 
@@ -68,72 +53,193 @@ func PluginPanics() []string {
 	return panics.allPanics()
 }
 
+func RegisterPluginPanicSink(w io.Writer) {
+	panics.Lock()
+	defer panics.Unlock()
+	panics.sink = w
+}
+
+const (
+	// maxRecordedPanicLines bounds the memory used by a single panic from a
+	// plugin that never stops writing to stderr.
+	maxRecordedPanicLines = 10000
+
+	// elidedGoroutineFrames is how many stack frames are kept for each
+	// goroutine other than the panicking one when a panic is truncated.
+	elidedGoroutineFrames = 5
+)
+
 type panicRecorder struct {
 	sync.Mutex
 
-	panics map[string][]string
+	// panics holds every panic seen from each plugin, in order, as the
+	// complete list of lines starting at the "panic:" line.
+	panics map[string][][]string
 
+	// maxLines is the size that each panic is truncated to when reported.
 	maxLines int
-}
-
-func (p *panicRecorder) registerPlugin(name string) func(string) {
-	p.Lock()
-	defer p.Unlock()
 
-	delete(p.panics, name)
+	// sink, if set, receives every recorded line prefixed with the plugin
+	// name and a tab, so that a parent process can recover plugin panics
+	// from a child that crashed.
+	sink io.Writer
+}
 
-	count := 0
+func newPanicRecorder(maxLines int) *panicRecorder {
+	return &panicRecorder{
+		panics:   make(map[string][][]string),
+		maxLines: maxLines,
+	}
+}
 
+// registerPlugin returns the function that records the stderr lines of one
+// plugin process. Each registration tracks its own panic, so that several
+// processes of the same plugin neither interleave nor cut short each
+// other's stack traces.
+func (p *panicRecorder) registerPlugin(name string) func(string) {
+	current := -1
 	return func(line string) {
 		p.Lock()
 		defer p.Unlock()
+		current = p.record(name, current, line)
+	}
+}
 
-		if count > p.maxLines {
-			return
-		}
-		count++
+// record adds line to the panic at index current of the plugin's panics,
+// starting a new panic first if line begins one, and returns the index of
+// the panic that the plugin's next line belongs to, or -1 if none.
+func (p *panicRecorder) record(name string, current int, line string) int {
+	if isPanicStart(line) {
+		p.panics[name] = append(p.panics[name], nil)
+		current = len(p.panics[name]) - 1
+	}
+	if current < 0 {
+		return current
+	}
+
+	if len(p.panics[name][current]) >= maxRecordedPanicLines {
+		return current
+	}
+	p.panics[name][current] = append(p.panics[name][current], line)
 
-		p.panics[name] = append(p.panics[name], line)
+	if p.sink != nil {
+		fmt.Fprintf(p.sink, "%s\t%s\n", name, line)
 	}
+	return current
 }
 
 func (p *panicRecorder) allPanics() []string {
 	p.Lock()
 	defer p.Unlock()
 
+	names := make([]string, 0, len(p.panics))
+	for name := range p.panics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	var res []string
-	for name, lines := range p.panics {
-		if len(lines) == 0 {
-			continue
+	for _, name := range names {
+		for _, lines := range p.panics[name] {
+			lines = truncatePanic(lines, p.maxLines)
+			res = append(res, fmt.Sprintf(pluginPanicOutput, name, strings.Join(lines, "\n")))
 		}
-		res = append(res, fmt.Sprintf(pluginPanicOutput, name, strings.Join(lines, "\n")))
 	}
 	return res
 }
 
+func isPanicStart(line string) bool {
+	return strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ")
+}
+
+// truncatePanic shortens a Go panic to roughly maxLines lines. The panic
+// message and the first goroutine, which is the one that panicked, are
+// always kept in full. Each other goroutine keeps its header and first few
+// frames until the line budget is spent, after which the remaining
+// goroutines are summarized by a count.
+func truncatePanic(lines []string, maxLines int) []string {
+	if len(lines) <= maxLines {
+		return lines
+	}
+
+	var blocks [][]string
+	start := 0
+	for i, line := range lines {
+		if i > 0 && strings.HasPrefix(line, "goroutine ") {
+			blocks = append(blocks, lines[start:i])
+			start = i
+		}
+	}
+	blocks = append(blocks, lines[start:])
+
+	// The first block is everything up to the first goroutine header and
+	// the second is the panicking goroutine itself.
+	keep := 2
+	if len(blocks) < keep {
+		keep = len(blocks)
+	}
+	var ret []string
+	for _, block := range blocks[:keep] {
+		ret = append(ret, block...)
+	}
+
+	others := blocks[keep:]
+	for i, block := range others {
+		short := block
+		// Frames are two lines each, following the one-line header.
+		if limit := 1 + 2*elidedGoroutineFrames; len(short) > limit {
+			short = append(append([]string(nil), block[:limit]...), "...additional frames elided...", "")
+		}
+		if len(ret)+len(short) > maxLines {
+			ret = append(ret, fmt.Sprintf("...%d additional goroutines elided...", len(others)-i))
+			break
+		}
+		ret = append(ret, short...)
+	}
+
+	return ret
+}
+
 type logPanicWrapper struct {
 	hclog.Logger
+
+	// panicRecorder is set once the wrapper has been named for a plugin,
+	// and is shared by all loggers derived from it.
 	panicRecorder func(string)
-	inPanic       bool
 }
 
 func (l *logPanicWrapper) Named(name string) hclog.Logger {
+	return l.derive(l.Logger.Named(name), name)
+}
+
+func (l *logPanicWrapper) ResetNamed(name string) hclog.Logger {
+	return l.derive(l.Logger.ResetNamed(name), name)
+}
+
+func (l *logPanicWrapper) With(args ...interface{}) hclog.Logger {
 	return &logPanicWrapper{
-		Logger:        l.Logger.Named(name),
-		panicRecorder: panics.registerPlugin(name),
+		Logger:        l.Logger.With(args...),
+		panicRecorder: l.panicRecorder,
 	}
 }
 
-func (l *logPanicWrapper) Debug(msg string, args ...interface{}) {
-	panicPrefix := strings.HasPrefix(msg, "panic: ") || strings.HasPrefix(msg, "fatal error: ")
-
-	l.inPanic = l.inPanic || panicPrefix
+func (l *logPanicWrapper) derive(logger hclog.Logger, name string) hclog.Logger {
+	recorder := l.panicRecorder
+	if recorder == nil {
+		recorder = panics.registerPlugin(name)
+	}
+	return &logPanicWrapper{
+		Logger:        logger,
+		panicRecorder: recorder,
+	}
+}
 
-	if l.inPanic && l.panicRecorder != nil {
+func (l *logPanicWrapper) Debug(msg string, args ...interface{}) {
+	if l.panicRecorder != nil {
 		l.panicRecorder(msg)
 	}
 
-	if panicPrefix {
+	if isPanicStart(msg) {
 		colon := strings.Index(msg, ":")
 		msg = strings.ToUpper(msg[:colon]) + msg[colon:]
 	}
@@ -0,0 +1,184 @@
+package logging
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-hclog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePanic returns the lines of a Go panic with the given number of
+// goroutines, each with the given number of stack frames.
+func fakePanic(msg string, goroutines, frames int) []string {
+	lines := []string{"panic: " + msg, ""}
+	for g := 1; g <= goroutines; g++ {
+		lines = append(lines, fmt.Sprintf("goroutine %d [running]:", g))
+		for f := 0; f < frames; f++ {
+			lines = append(lines, fmt.Sprintf("main.f%d()", f), fmt.Sprintf("\t/src/main.go:%d +0x1", f))
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func TestPanicRecorder_concurrentPlugins(t *testing.T) {
+	defer func(old *panicRecorder) { panics = old }(panics)
+	panics = newPanicRecorder(1000)
+
+	const plugins = 8
+	base := &logPanicWrapper{Logger: hclog.NewNullLogger()}
+
+	var wg sync.WaitGroup
+	for i := 0; i < plugins; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			logger := base.Named(fmt.Sprintf("plugin%d", i)).With("pid", i)
+			logger.Debug("starting up")
+			for _, line := range fakePanic(fmt.Sprintf("plugin%d failed", i), 3, 4) {
+				logger.Debug(line)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got := PluginPanics()
+	if len(got) != plugins {
+		t.Fatalf("got %d panics, want %d", len(got), plugins)
+	}
+	for i, msg := range got {
+		want := strings.Join(fakePanic(fmt.Sprintf("plugin%d failed", i), 3, 4), "\n")
+		if !strings.Contains(msg, want) {
+			t.Errorf("panic %d does not contain the complete stack trace:\n%s", i, msg)
+		}
+		if strings.Contains(msg, "starting up") {
+			t.Errorf("panic %d includes a line logged before the panic:\n%s", i, msg)
+		}
+	}
+}
+
+func TestPanicRecorder_sameNameProcesses(t *testing.T) {
+	rec := newPanicRecorder(1000)
+
+	const processes = 4
+	var wg sync.WaitGroup
+	for i := 0; i < processes; i++ {
+		record := rec.registerPlugin("aws")
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, line := range fakePanic(fmt.Sprintf("process %d", i), 2, 3) {
+				record(line)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := len(rec.panics["aws"]); got != processes {
+		t.Fatalf("got %d panics, want %d", got, processes)
+	}
+	for _, lines := range rec.panics["aws"] {
+		var i int
+		if _, err := fmt.Sscanf(lines[0], "panic: process %d", &i); err != nil {
+			t.Fatalf("unexpected first line %q", lines[0])
+		}
+		if want := fakePanic(fmt.Sprintf("process %d", i), 2, 3); !reflect.DeepEqual(lines, want) {
+			t.Errorf("wrong lines for process %d\ngot:  %q\nwant: %q", i, lines, want)
+		}
+	}
+}
+
+func TestPanicRecorder_registerDuringPanic(t *testing.T) {
+	rec := newPanicRecorder(1000)
+
+	first := rec.registerPlugin("aws")
+	first("panic: boom")
+	second := rec.registerPlugin("aws")
+	second("ordinary output")
+	first("goroutine 1 [running]:")
+
+	want := [][]string{{"panic: boom", "goroutine 1 [running]:"}}
+	if got := rec.panics["aws"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong panics\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestPanicRecorder_maxRecordedLines(t *testing.T) {
+	rec := newPanicRecorder(maxRecordedPanicLines)
+
+	record := rec.registerPlugin("aws")
+	record("panic: boom")
+	for i := 0; i < maxRecordedPanicLines+10; i++ {
+		record("more output")
+	}
+
+	if got := len(rec.panics["aws"][0]); got != maxRecordedPanicLines {
+		t.Errorf("recorded %d lines, want %d", got, maxRecordedPanicLines)
+	}
+}
+
+func TestPanicRecorder_sink(t *testing.T) {
+	rec := newPanicRecorder(1000)
+	var buf strings.Builder
+	rec.sink = &buf
+
+	record := rec.registerPlugin("aws")
+	record("before the panic")
+	record("panic: boom")
+	record("goroutine 1 [running]:")
+
+	want := "aws\tpanic: boom\naws\tgoroutine 1 [running]:\n"
+	if got := buf.String(); got != want {
+		t.Errorf("wrong sink output\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestTruncatePanic(t *testing.T) {
+	long := fakePanic("boom", 5, 10)
+
+	tests := map[string]struct {
+		lines    []string
+		maxLines int
+		want     []string
+	}{
+		"within limit": {
+			lines:    fakePanic("boom", 2, 1),
+			maxLines: 100,
+			want:     fakePanic("boom", 2, 1),
+		},
+		"only the panicking goroutine fits": {
+			lines:    long,
+			maxLines: 30,
+			want: append(append([]string(nil), long[:24]...),
+				"...4 additional goroutines elided...",
+			),
+		},
+		"frames of other goroutines elided": {
+			lines:    long,
+			maxLines: 40,
+			want: append(append(append([]string(nil), long[:24]...), long[24:35]...),
+				"...additional frames elided...",
+				"",
+				"...3 additional goroutines elided...",
+			),
+		},
+		"panicking goroutine kept when over limit": {
+			lines:    long,
+			maxLines: 5,
+			want: append(append([]string(nil), long[:24]...),
+				"...4 additional goroutines elided...",
+			),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := truncatePanic(test.lines, test.maxLines)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package logging
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	envLogRedactPattern = "TF_LOG_REDACT_PATTERN"
+
+	redactedText = "[REDACTED]"
+
+	// minSecretLength stops very short values from being registered as
+	// secrets, which would otherwise mask unrelated text all over the log.
+	minSecretLength = 4
+)
+
+var redactor = newRedactor()
+
+// defaultRedactPatterns mask the credentials in Authorization headers,
+// whether logged as raw HTTP, as JSON, or as an http.Header formatted with
+// %v or %#v.
+var defaultRedactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)authorization"?\s*[:=]\s*(?:\[\]string\{|\[)?"?(?:(?:bearer|basic|token)\s+)?([^\s"',{}\[\]]+)`),
+}
+
+type logRedactor struct {
+	sync.RWMutex
+
+	secrets  []string
+	patterns []*regexp.Regexp
+}
+
+func newRedactor() *logRedactor {
+	r := &logRedactor{
+		patterns: append([]*regexp.Regexp(nil), defaultRedactPatterns...),
+	}
+
+	if raw := os.Getenv(envLogRedactPattern); raw != "" {
+		re, err := regexp.Compile(raw)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] Invalid %s: %s\n", envLogRedactPattern, err)
+		} else {
+			r.patterns = append(r.patterns, re)
+		}
+	}
+
+	return r
+}
+
+// RegisterSecret arranges for every later occurrence of the given value in
+// log and crash output to be masked.
+func RegisterSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	redactor.Lock()
+	defer redactor.Unlock()
+	for _, existing := range redactor.secrets {
+		if existing == secret {
+			return
+		}
+	}
+	redactor.secrets = append(redactor.secrets, secret)
+}
+
+// AddRedactPattern masks text matching the given expression. If the
+// expression has capture groups then only the captured text is masked,
+// otherwise the whole match is.
+func AddRedactPattern(re *regexp.Regexp) {
+	redactor.Lock()
+	defer redactor.Unlock()
+	redactor.patterns = append(redactor.patterns, re)
+}
+
+func (r *logRedactor) redact(s string) string {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, secret := range r.secrets {
+		s = strings.Replace(s, secret, redactedText, -1)
+	}
+	for _, re := range r.patterns {
+		s = redactPattern(re, s)
+	}
+	return s
+}
+
+func redactPattern(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, redactedText)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(s, -1) {
+		for i := 2; i < len(match); i += 2 {
+			start, end := match[i], match[i+1]
+			if start < last || start == end {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(redactedText)
+			last = end
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+type redactingWriter struct {
+	w io.Writer
+}
+
+func newRedactingWriter(w io.Writer) io.Writer {
+	return &redactingWriter{w: w}
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, redactor.redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// copyRedacted copies src to dst line by line so that a secret can never be
+// split across two separately-redacted chunks.
+func copyRedacted(dst io.Writer, src io.Reader) error {
+	r := bufio.NewReader(src)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			if _, werr := io.WriteString(dst, redactor.redact(line)); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestLogRedactor_defaultPatterns(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc.def-123")
+
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"raw header": {
+			input: "Authorization: Bearer abc.def-123\r\n",
+			want:  "Authorization: Bearer [REDACTED]\r\n",
+		},
+		"raw header without a scheme": {
+			input: "authorization: abc.def-123",
+			want:  "authorization: [REDACTED]",
+		},
+		"basic auth": {
+			input: "Authorization: Basic dXNlcjpwYXNz",
+			want:  "Authorization: Basic [REDACTED]",
+		},
+		"http.Header with %v": {
+			input: fmt.Sprintf("headers: %v", header),
+			want:  "headers: map[Authorization:[Bearer [REDACTED]]]",
+		},
+		"http.Header with %#v": {
+			input: fmt.Sprintf("headers: %#v", header),
+			want:  `headers: http.Header{"Authorization":[]string{"Bearer [REDACTED]"}}`,
+		},
+		"JSON": {
+			input: `{"Authorization": "Bearer abc.def-123", "Accept": "*/*"}`,
+			want:  `{"Authorization": "Bearer [REDACTED]", "Accept": "*/*"}`,
+		},
+		"JSON array": {
+			input: `{"Authorization":["token abc.def-123"]}`,
+			want:  `{"Authorization":["token [REDACTED]"]}`,
+		},
+		"unrelated text": {
+			input: "Accept: application/json",
+			want:  "Accept: application/json",
+		},
+	}
+
+	r := &logRedactor{patterns: defaultRedactPatterns}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := r.redact(test.input); got != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLogRedactor_customPatterns(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		input   string
+		want    string
+	}{
+		"no capture groups": {
+			pattern: `secret-[0-9]+`,
+			input:   "first secret-123, then secret-456",
+			want:    "first [REDACTED], then [REDACTED]",
+		},
+		"capture group": {
+			pattern: `password=(\S+)`,
+			input:   "user=admin password=hunter2 port=22",
+			want:    "user=admin password=[REDACTED] port=22",
+		},
+		"several capture groups": {
+			pattern: `key=(\w+) secret=(\w+)`,
+			input:   "key=AKIA1234 secret=abcd",
+			want:    "key=[REDACTED] secret=[REDACTED]",
+		},
+		"empty capture group": {
+			pattern: `token=(\w*)`,
+			input:   "token= and token=abcd",
+			want:    "token= and token=[REDACTED]",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &logRedactor{patterns: []*regexp.Regexp{regexp.MustCompile(test.pattern)}}
+			if got := r.redact(test.input); got != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRegisterSecret(t *testing.T) {
+	defer func(secrets []string) {
+		redactor.Lock()
+		redactor.secrets = secrets
+		redactor.Unlock()
+	}(redactor.secrets)
+
+	RegisterSecret("abc")
+	RegisterSecret("s3cr3t-token")
+	RegisterSecret("s3cr3t-token")
+
+	got := redactor.redact("abc s3cr3t-token and s3cr3t-token again")
+	if want := "abc [REDACTED] and [REDACTED] again"; got != want {
+		t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+	}
+
+	count := 0
+	for _, secret := range redactor.secrets {
+		if secret == "s3cr3t-token" {
+			count++
+		}
+		if len(secret) < minSecretLength {
+			t.Errorf("secret %q registered although it is too short", secret)
+		}
+	}
+	if count != 1 {
+		t.Errorf("secret registered %d times", count)
+	}
+}
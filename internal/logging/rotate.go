@@ -0,0 +1,220 @@
+package logging
+
+import (
+	"fmt"
+	"github.com/mitchellh/panicwrap"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	envLogFileMaxSize  = "TF_LOG_PATH_MAX_SIZE"
+	envLogFileMaxAge   = "TF_LOG_PATH_MAX_AGE"
+	envLogFileMaxFiles = "TF_LOG_PATH_MAX_FILES"
+
+	// defaultLogFileMode only applies when the log file is created. An
+	// existing log file keeps whatever permissions it already has.
+	defaultLogFileMode     os.FileMode = 0600
+	defaultLogFileMaxFiles             = 5
+)
+
+// rotatingFile is an io.Writer that appends to the file at path, moving it
+// aside to path.1, path.2, and so on once it grows beyond maxSize bytes or
+// is older than maxAge. A zero maxSize or maxAge disables that
+// trigger, and only maxFiles old files are kept.
+type rotatingFile struct {
+	sync.Mutex
+
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+
+	f        *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func openRotatingFile(path string, maxSize int64, maxAge time.Duration, maxFiles int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:     path,
+		maxSize:  maxSize,
+		maxAge:   maxAge,
+		maxFiles: maxFiles,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			// Whatever stopped the rotation, such as another process
+			// holding the file open on Windows, is unlikely to go away,
+			// so rather than failing again for every line the file just
+			// keeps growing.
+			fmt.Fprintf(os.Stderr, "Error rotating log file, so it will no longer be rotated: %v\n", err)
+			r.maxSize, r.maxAge = 0, 0
+		}
+	}
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (r *rotatingFile) shouldRotate(next int64) bool {
+	if r.f == nil {
+		return false
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+next > r.maxSize {
+		return true
+	}
+	if r.maxAge > 0 && time.Since(r.openedAt) > r.maxAge {
+		return true
+	}
+	return false
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	if r.maxFiles > 0 {
+		os.Remove(r.backupPath(r.maxFiles))
+		for i := r.maxFiles - 1; i >= 1; i-- {
+			err := os.Rename(r.backupPath(i), r.backupPath(i+1))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(r.path, r.backupPath(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, syscall.O_CREAT|syscall.O_RDWR|syscall.O_APPEND, defaultLogFileMode)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	if r.size > 0 && info.ModTime().Before(r.openedAt) {
+		// A leftover file from an earlier run is at least as old as its
+		// last write, so a stale log is rotated on the first new write.
+		r.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (r *rotatingFile) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+func openLogFile(path string) (*rotatingFile, error) {
+	maxSize, err := parseByteSize(os.Getenv(envLogFileMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envLogFileMaxSize, err)
+	}
+
+	var maxAge time.Duration
+	if raw := os.Getenv(envLogFileMaxAge); raw != "" {
+		maxAge, err = time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", envLogFileMaxAge, err)
+		}
+	}
+
+	maxFiles := defaultLogFileMaxFiles
+	if raw := os.Getenv(envLogFileMaxFiles); raw != "" {
+		maxFiles, err = strconv.Atoi(raw)
+		if err != nil || maxFiles < 0 {
+			return nil, fmt.Errorf("invalid %s: must be a non-negative integer", envLogFileMaxFiles)
+		}
+	}
+
+	if !rotatesLogFile() {
+		return openRotatingFile(path, 0, 0, 0)
+	}
+	return openRotatingFile(path, maxSize, maxAge, maxFiles)
+}
+
+// rotatesLogFile reports whether this process is the one that rotates the
+// log file. Under panicwrap both the parent and the child write to it, and
+// if both rotated it each would rename the file out from under the other,
+// so only the child does. The parent logs very little, and after the child
+// rotates the file the parent's lines go to the first backup.
+func rotatesLogFile() bool {
+	if os.Getenv("TF_FORK") == "0" {
+		return true
+	}
+	return os.Getenv(panicwrap.DEFAULT_COOKIE_KEY) == panicwrap.DEFAULT_COOKIE_VAL
+}
+
+// parseByteSize accepts a plain number of bytes or a number followed by
+// one of the suffixes K, M or G (powers of 1024).
+func parseByteSize(raw string) (int64, error) {
+	raw = strings.ToUpper(strings.TrimSpace(raw))
+	if raw == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(raw, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(raw, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(raw, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier != 1 {
+		raw = raw[:len(raw)-1]
+	}
+
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a valid size", raw)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,343 @@
+package logging
+
+import (
+	"github.com/mitchellh/panicwrap"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setenv sets an environment variable for the rest of the test, and
+// returns a function that puts back its original value.
+func setenv(key, value string) func() {
+	old, ok := os.LookupEnv(key)
+	if value == "" {
+		os.Unsetenv(key)
+	} else {
+		os.Setenv(key, value)
+	}
+	return func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func tempLogPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "logging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "terraform.log"), func() { os.RemoveAll(dir) }
+}
+
+// logFiles returns the contents of the log file and each of its backups
+// that exist, by name relative to the log file.
+func logFiles(t *testing.T, path string) map[string]string {
+	ret := make(map[string]string)
+	matches, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, match := range matches {
+		src, err := ioutil.ReadFile(match)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret[match[len(path):]] = string(src)
+	}
+	return ret
+}
+
+func writeLines(t *testing.T, w *rotatingFile, lines ...string) {
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("write failed: %s", err)
+		}
+	}
+}
+
+func TestRotatingFile_size(t *testing.T) {
+	path, cleanup := tempLogPath(t)
+	defer cleanup()
+
+	r, err := openRotatingFile(path, 10, 0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, r, "one\n", "two\n", "three\n", "a line longer than the limit\n", "four\n")
+	r.Close()
+
+	want := map[string]string{
+		"":   "four\n",
+		".1": "a line longer than the limit\n",
+		".2": "three\n",
+		".3": "one\ntwo\n",
+	}
+	if got := logFiles(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong files\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestRotatingFile_age(t *testing.T) {
+	path, cleanup := tempLogPath(t)
+	defer cleanup()
+
+	r, err := openRotatingFile(path, 0, time.Hour, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, r, "one\n", "two\n")
+	r.openedAt = time.Now().Add(-2 * time.Hour)
+	writeLines(t, r, "three\n")
+	r.Close()
+
+	want := map[string]string{
+		"":   "three\n",
+		".1": "one\ntwo\n",
+	}
+	if got := logFiles(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong files\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestRotatingFile_staleFile(t *testing.T) {
+	path, cleanup := tempLogPath(t)
+	defer cleanup()
+
+	if err := ioutil.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := openRotatingFile(path, 0, time.Hour, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, r, "new\n")
+	r.Close()
+
+	want := map[string]string{
+		"":   "new\n",
+		".1": "old\n",
+	}
+	if got := logFiles(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong files\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestRotatingFile_maxFiles(t *testing.T) {
+	path, cleanup := tempLogPath(t)
+	defer cleanup()
+
+	r, err := openRotatingFile(path, 5, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, r, "1111\n", "2222\n", "3333\n", "4444\n", "5555\n")
+	r.Close()
+
+	want := map[string]string{
+		"":   "5555\n",
+		".1": "4444\n",
+		".2": "3333\n",
+	}
+	if got := logFiles(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong files\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestRotatingFile_failure(t *testing.T) {
+	path, cleanup := tempLogPath(t)
+	defer cleanup()
+
+	// A non-empty directory where the backup should go can be neither
+	// removed nor replaced, so the rotation fails.
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := openRotatingFile(path, 5, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stderr := os.Stderr
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = errW
+	writeLines(t, r, "1111\n", "2222\n", "3333\n", "4444\n")
+	os.Stderr = stderr
+	errW.Close()
+	r.Close()
+
+	errors, err := ioutil.ReadAll(errR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(errors), "\n"); got != 1 {
+		t.Errorf("got %d errors, want 1:\n%s", got, errors)
+	}
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(src), "1111\n2222\n3333\n4444\n"; got != want {
+		t.Errorf("wrong log file %q; want %q", got, want)
+	}
+}
+
+func TestOpenLogFile(t *testing.T) {
+	tests := map[string]struct {
+		fork, cookie string
+		maxSize      string
+		maxFiles     string
+		want         map[string]string
+	}{
+		"child": {
+			cookie:  panicwrap.DEFAULT_COOKIE_VAL,
+			maxSize: "5",
+			want: map[string]string{
+				"":   "3333\n",
+				".1": "2222\n",
+				".2": "1111\n",
+			},
+		},
+		"not forked": {
+			fork:    "0",
+			maxSize: "5",
+			want: map[string]string{
+				"":   "3333\n",
+				".1": "2222\n",
+				".2": "1111\n",
+			},
+		},
+		"panicwrap parent": {
+			maxSize: "5",
+			want: map[string]string{
+				"": "1111\n2222\n3333\n",
+			},
+		},
+		"no backups": {
+			cookie:   panicwrap.DEFAULT_COOKIE_VAL,
+			maxSize:  "5",
+			maxFiles: "0",
+			want: map[string]string{
+				"": "3333\n",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer setenv("TF_FORK", test.fork)()
+			defer setenv(panicwrap.DEFAULT_COOKIE_KEY, test.cookie)()
+			defer setenv(envLogFileMaxSize, test.maxSize)()
+			defer setenv(envLogFileMaxAge, "")()
+			defer setenv(envLogFileMaxFiles, test.maxFiles)()
+
+			path, cleanup := tempLogPath(t)
+			defer cleanup()
+
+			r, err := openLogFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeLines(t, r, "1111\n", "2222\n", "3333\n")
+			r.Close()
+
+			if got := logFiles(t, path); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong files\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOpenLogFile_invalid(t *testing.T) {
+	tests := map[string]struct {
+		maxSize, maxAge, maxFiles string
+	}{
+		"size":  {maxSize: "lots"},
+		"age":   {maxAge: "a week"},
+		"files": {maxFiles: "-1"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer setenv(envLogFileMaxSize, test.maxSize)()
+			defer setenv(envLogFileMaxAge, test.maxAge)()
+			defer setenv(envLogFileMaxFiles, test.maxFiles)()
+
+			path, cleanup := tempLogPath(t)
+			defer cleanup()
+
+			if _, err := openLogFile(path); err == nil {
+				t.Error("succeeded; want error")
+			}
+		})
+	}
+}
+
+func TestRotatesLogFile(t *testing.T) {
+	tests := map[string]struct {
+		fork, cookie string
+		want         bool
+	}{
+		"panicwrap parent": {want: false},
+		"panicwrap child":  {cookie: panicwrap.DEFAULT_COOKIE_VAL, want: true},
+		"wrong cookie":     {cookie: "nope", want: false},
+		"not forked":       {fork: "0", want: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer setenv("TF_FORK", test.fork)()
+			defer setenv(panicwrap.DEFAULT_COOKIE_KEY, test.cookie)()
+
+			if got := rotatesLogFile(); got != test.want {
+				t.Errorf("wrong result %t; want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"512", 512, false},
+		{" 10k ", 10 << 10, false},
+		{"10K", 10 << 10, false},
+		{"5M", 5 << 20, false},
+		{"2g", 2 << 30, false},
+		{"1.5M", 0, true},
+		{"-1", 0, true},
+		{"M", 0, true},
+		{"10KB", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := parseByteSize(test.raw)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseByteSize(%q) returned error %v", test.raw, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseByteSize(%q) = %d; want %d", test.raw, got, test.want)
+		}
+	}
+}
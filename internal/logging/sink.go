@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"github.com/hashicorp/go-hclog"
+	"io"
+	"strings"
+)
+
+type SinkOptions struct {
+	// Level is the minimum level of messages written to the sink.
+	Level hclog.Level
+
+	JSONFormat bool
+
+	// Modules, if not empty, limits the sink to messages from the named
+	// loggers and their descendants, such as "provider" for every provider
+	// plugin. The root logger has the empty name.
+	Modules []string
+}
+
+// Sink is a handle for an output added with AddSink, used to remove it
+// again with RemoveSink.
+type Sink struct {
+	adapter hclog.SinkAdapter
+}
+
+func AddSink(w io.Writer, opts SinkOptions) *Sink {
+	adapter := hclog.NewSinkAdapter(&hclog.LoggerOptions{
+		Level:      opts.Level,
+		Output:     newRedactingWriter(w),
+		JSONFormat: opts.JSONFormat,
+	})
+	if len(opts.Modules) > 0 {
+		adapter = &moduleFilterSink{
+			modules: opts.Modules,
+			adapter: adapter,
+		}
+	}
+
+	interceptLogger().RegisterSink(adapter)
+	return &Sink{adapter: adapter}
+}
+
+func RemoveSink(s *Sink) {
+	if s == nil {
+		return
+	}
+	interceptLogger().DeregisterSink(s.adapter)
+}
+
+func interceptLogger() hclog.InterceptLogger {
+	l, ok := logger.(hclog.InterceptLogger)
+	if !ok {
+		panic("global logger is not an InterceptLogger")
+	}
+	return l
+}
+
+type moduleFilterSink struct {
+	modules []string
+	adapter hclog.SinkAdapter
+}
+
+func (s *moduleFilterSink) Accept(name string, level hclog.Level, msg string, args ...interface{}) {
+	for _, module := range s.modules {
+		if name == module || (module != "" && strings.HasPrefix(name, module+".")) {
+			s.adapter.Accept(name, level, msg, args...)
+			return
+		}
+	}
+}
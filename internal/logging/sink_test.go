@@ -0,0 +1,139 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/hashicorp/go-hclog"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAddSink_level(t *testing.T) {
+	var buf bytes.Buffer
+	sink := AddSink(&buf, SinkOptions{Level: hclog.Warn})
+	defer RemoveSink(sink)
+
+	l := NewLogger("sinktest")
+	l.Info("info message")
+	l.Warn("warn message")
+	l.Error("error message")
+
+	got := buf.String()
+	if strings.Contains(got, "info message") {
+		t.Errorf("sink received a message below its level:\n%s", got)
+	}
+	for _, want := range []string{"[WARN]  sinktest: warn message", "[ERROR] sinktest: error message"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("sink output does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestAddSink_jsonFormat(t *testing.T) {
+	var buf bytes.Buffer
+	sink := AddSink(&buf, SinkOptions{Level: hclog.Info, JSONFormat: true})
+	defer RemoveSink(sink)
+
+	NewLogger("sinktest").Info("hello", "key", "value")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("sink output is not JSON: %s\n%s", err, buf.String())
+	}
+	want := map[string]interface{}{
+		"@level":   "info",
+		"@message": "hello",
+		"@module":  "sinktest",
+		"key":      "value",
+	}
+	delete(got, "@timestamp")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong JSON\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestAddSink_modules(t *testing.T) {
+	loggers := map[string]hclog.Logger{
+		"":             logger,
+		"provider":     NewLogger("provider"),
+		"provider.aws": NewLogger("provider").Named("aws"),
+		"providerx":    NewLogger("providerx"),
+		"installer":    NewLogger("installer"),
+	}
+
+	tests := map[string]struct {
+		modules []string
+		want    []string
+	}{
+		"root only": {
+			modules: []string{""},
+			want:    []string{""},
+		},
+		"module and descendants": {
+			modules: []string{"provider"},
+			want:    []string{"provider", "provider.aws"},
+		},
+		"descendant only": {
+			modules: []string{"provider.aws"},
+			want:    []string{"provider.aws"},
+		},
+		"several modules": {
+			modules: []string{"", "installer"},
+			want:    []string{"", "installer"},
+		},
+		"no filter": {
+			want: []string{"", "installer", "provider", "provider.aws", "providerx"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			sink := AddSink(&buf, SinkOptions{Level: hclog.Info, JSONFormat: true, Modules: test.modules})
+			defer RemoveSink(sink)
+
+			for name, l := range loggers {
+				l.Info("from " + name)
+			}
+
+			got := []string{}
+			dec := json.NewDecoder(&buf)
+			for dec.More() {
+				var line struct {
+					Message string `json:"@message"`
+				}
+				if err := dec.Decode(&line); err != nil {
+					t.Fatalf("sink output is not JSON: %s", err)
+				}
+				got = append(got, strings.TrimPrefix(line.Message, "from "))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong modules\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemoveSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := AddSink(&buf, SinkOptions{Level: hclog.Info})
+
+	l := NewLogger("sinktest")
+	l.Info("before")
+	RemoveSink(sink)
+	l.Info("after")
+
+	got := buf.String()
+	if !strings.Contains(got, "before") {
+		t.Errorf("sink did not receive a message before removal:\n%s", got)
+	}
+	if strings.Contains(got, "after") {
+		t.Errorf("sink received a message after removal:\n%s", got)
+	}
+
+	// Removing a nil sink is a no-op.
+	RemoveSink(nil)
+}
@@ -0,0 +1,90 @@
+package terminal
+
+import (
+	"os"
+	"strings"
+)
+
+// ColorDepth is the range of colors an output stream can display.
+type ColorDepth int
+
+const (
+	ColorNone ColorDepth = iota
+	Color16
+	Color256
+	ColorTrue
+)
+
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorNone:
+		return "none"
+	case Color16:
+		return "16"
+	case Color256:
+		return "256"
+	case ColorTrue:
+		return "truecolor"
+	default:
+		return "unknown"
+	}
+}
+
+// ColorDepth reports how many colors the stream can display.
+//
+// NO_COLOR disables color entirely. FORCE_COLOR overrides detection, even
+// for a stream that is not a terminal: it may be set to 0 to disable
+// color, or to 1, 2 or 3 to select 16 colors, 256 colors or truecolor.
+// Otherwise a terminal's capabilities are taken from COLORTERM and TERM,
+// or from the console itself on Windows.
+func (s *OutputStream) ColorDepth() ColorDepth {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorNone
+	}
+	if depth, ok := forcedColorDepth(os.Getenv("FORCE_COLOR")); ok {
+		return depth
+	}
+	if !s.IsTerminal() {
+		return ColorNone
+	}
+	if s.getColorDepth != nil {
+		return s.getColorDepth(s.File)
+	}
+	return colorDepthFromEnv()
+}
+
+func forcedColorDepth(raw string) (ColorDepth, bool) {
+	switch strings.ToLower(raw) {
+	case "":
+		return ColorNone, false
+	case "0", "false":
+		return ColorNone, true
+	case "2":
+		return Color256, true
+	case "3":
+		return ColorTrue, true
+	default:
+		return Color16, true
+	}
+}
+
+func colorDepthFromEnv() ColorDepth {
+	term := strings.ToLower(os.Getenv("TERM"))
+	if term == "" || term == "dumb" {
+		return ColorNone
+	}
+
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorTrue
+	}
+
+	switch {
+	case strings.Contains(term, "truecolor"), strings.Contains(term, "24bit"), strings.HasSuffix(term, "-direct"):
+		return ColorTrue
+	case strings.Contains(term, "256"):
+		return Color256
+	default:
+		return Color16
+	}
+}
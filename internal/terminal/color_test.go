@@ -0,0 +1,76 @@
+package terminal
+
+import (
+	"os"
+	"testing"
+)
+
+func TestOutputStreamColorDepth(t *testing.T) {
+	for _, name := range []string{"NO_COLOR", "FORCE_COLOR"} {
+		defer func(name, old string, ok bool) {
+			if ok {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		}(name, os.Getenv(name), os.Getenv(name) != "")
+		os.Unsetenv(name)
+	}
+
+	tests := map[string]struct {
+		term       TestTerminal
+		noColor    string
+		forceColor string
+		want       ColorDepth
+	}{
+		"terminal": {
+			term: TestTerminal{StdoutIsTerminal: true, ColorDepth: Color256},
+			want: Color256,
+		},
+		"not a terminal": {
+			term: TestTerminal{ColorDepth: Color256},
+			want: ColorNone,
+		},
+		"NO_COLOR": {
+			term:    TestTerminal{StdoutIsTerminal: true, ColorDepth: ColorTrue},
+			noColor: "1",
+			want:    ColorNone,
+		},
+		"FORCE_COLOR on a terminal": {
+			term:       TestTerminal{StdoutIsTerminal: true, ColorDepth: Color16},
+			forceColor: "3",
+			want:       ColorTrue,
+		},
+		"FORCE_COLOR when not a terminal": {
+			term:       TestTerminal{},
+			forceColor: "1",
+			want:       Color16,
+		},
+		"FORCE_COLOR disabling color": {
+			term:       TestTerminal{StdoutIsTerminal: true, ColorDepth: ColorTrue},
+			forceColor: "0",
+			want:       ColorNone,
+		},
+		"NO_COLOR wins over FORCE_COLOR": {
+			term:       TestTerminal{StdoutIsTerminal: true, ColorDepth: ColorTrue},
+			noColor:    "1",
+			forceColor: "2",
+			want:       ColorNone,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.Setenv("NO_COLOR", test.noColor)
+			os.Setenv("FORCE_COLOR", test.forceColor)
+			defer os.Unsetenv("NO_COLOR")
+			defer os.Unsetenv("FORCE_COLOR")
+
+			streams, close := StreamsForTesting(t, test.term)
+			defer close(t)
+			if got := streams.Stdout.ColorDepth(); got != test.want {
+				t.Errorf("wrong color depth %s; want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+)
+
+// consoleAPI is the part of the Windows console API that the terminal
+// setup relies on. The setup is written against this interface rather than
+// the real API so that it can be exercised with a fake console on any
+// platform.
+type consoleAPI interface {
+	IsTerminal(handle uintptr) bool
+	IsCygwinTerminal(handle uintptr) bool
+	GetConsoleMode(handle uintptr) (uint32, error)
+	SetConsoleMode(handle uintptr, mode uint32) error
+	SetConsoleCP(codepage uint32) error
+	SetConsoleOutputCP(codepage uint32) error
+	ConsoleSize(handle uintptr) (columns, rows int, err error)
+}
+
+const (
+	CP_UTF8 = 65001
+
+	enableVirtualTerminalProcessing uint32 = 0x0004
+)
+
+func configureConsoleOutput(api consoleAPI, f *os.File) (*OutputStream, error) {
+	ret := &OutputStream{
+		File: f,
+	}
+	handle := f.Fd()
+
+	if api.IsCygwinTerminal(handle) {
+		ret.isTerminal = staticTrue
+		return ret, nil
+	}
+	if !api.IsTerminal(handle) {
+		return ret, nil
+	}
+
+	if err := api.SetConsoleOutputCP(CP_UTF8); err != nil {
+		return nil, fmt.Errorf("failed to set the console to UTF-8 mode; you may need to use a newer version of Windows: %s", err)
+	}
+	ret.isTerminal = staticTrue
+	ret.getColumns = func(f *os.File) int {
+		columns, _, err := api.ConsoleSize(f.Fd())
+		if err != nil || columns <= 0 {
+			return defaultColumns
+		}
+		return columns
+	}
+	ret.getRows = func(f *os.File) int {
+		_, rows, err := api.ConsoleSize(f.Fd())
+		if err != nil || rows <= 0 {
+			return defaultRows
+		}
+		return rows
+	}
+
+	// Every console that supports virtual terminal processing can display
+	// truecolor. Without it escape sequences are printed literally, and
+	// since the UI only styles text with escape sequences we then use no
+	// color at all.
+	ret.getColorDepth = func(f *os.File) ColorDepth {
+		mode, err := api.GetConsoleMode(f.Fd())
+		if err != nil || mode&enableVirtualTerminalProcessing == 0 {
+			return ColorNone
+		}
+		return ColorTrue
+	}
+
+	mode, err := api.GetConsoleMode(handle)
+	if err != nil || mode&enableVirtualTerminalProcessing != 0 {
+		return ret, nil
+	}
+	if err := api.SetConsoleMode(handle, mode|enableVirtualTerminalProcessing); err != nil {
+		// Versions of Windows before 10 don't support virtual terminal
+		// processing, so we just leave the console as it was.
+		return ret, nil
+	}
+	ret.restore = func() error {
+		return api.SetConsoleMode(handle, mode)
+	}
+
+	return ret, nil
+}
+
+func configureConsoleInput(api consoleAPI, f *os.File) (*InputStream, error) {
+	ret := &InputStream{
+		File: f,
+	}
+	handle := f.Fd()
+
+	if api.IsCygwinTerminal(handle) {
+		ret.isTerminal = staticTrue
+		return ret, nil
+	}
+	if !api.IsTerminal(handle) {
+		return ret, nil
+	}
+
+	if err := api.SetConsoleCP(CP_UTF8); err != nil {
+		return nil, fmt.Errorf("failed to set the console to UTF-8 mode; you may need to use a newer version of Windows: %s", err)
+	}
+	ret.isTerminal = staticTrue
+
+	return ret, nil
+}
+
+func staticTrue(f *os.File) bool {
+	return true
+}
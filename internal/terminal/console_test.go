@@ -0,0 +1,217 @@
+package terminal
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// fakeConsole is a consoleAPI for a single console handle.
+type fakeConsole struct {
+	handle   uintptr
+	terminal bool
+	cygwin   bool
+	mode     uint32
+
+	// setModeErr, if set, is returned by SetConsoleMode instead of
+	// changing the mode.
+	setModeErr error
+
+	inputCP, outputCP uint32
+}
+
+var _ consoleAPI = (*fakeConsole)(nil)
+
+func (c *fakeConsole) IsTerminal(handle uintptr) bool {
+	return handle == c.handle && c.terminal
+}
+
+func (c *fakeConsole) IsCygwinTerminal(handle uintptr) bool {
+	return handle == c.handle && c.cygwin
+}
+
+func (c *fakeConsole) GetConsoleMode(handle uintptr) (uint32, error) {
+	if handle != c.handle || !c.terminal {
+		return 0, errors.New("not a console")
+	}
+	return c.mode, nil
+}
+
+func (c *fakeConsole) SetConsoleMode(handle uintptr, mode uint32) error {
+	if handle != c.handle || !c.terminal {
+		return errors.New("not a console")
+	}
+	if c.setModeErr != nil {
+		return c.setModeErr
+	}
+	c.mode = mode
+	return nil
+}
+
+func (c *fakeConsole) SetConsoleCP(codepage uint32) error {
+	c.inputCP = codepage
+	return nil
+}
+
+func (c *fakeConsole) SetConsoleOutputCP(codepage uint32) error {
+	c.outputCP = codepage
+	return nil
+}
+
+func (c *fakeConsole) ConsoleSize(handle uintptr) (int, int, error) {
+	return 120, 40, nil
+}
+
+// Arbitrary console mode flags that must be kept as they are.
+const (
+	enableProcessedOutput uint32 = 0x0001
+	enableWrapAtEOLOutput uint32 = 0x0002
+)
+
+func testConsoleFile(t *testing.T) *os.File {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	return w
+}
+
+func TestConfigureConsoleOutput(t *testing.T) {
+	f := testConsoleFile(t)
+	defer f.Close()
+	console := &fakeConsole{
+		handle:   f.Fd(),
+		terminal: true,
+		mode:     enableProcessedOutput | enableWrapAtEOLOutput,
+	}
+
+	stream, err := configureConsoleOutput(console, f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := console.mode, enableProcessedOutput|enableWrapAtEOLOutput|enableVirtualTerminalProcessing; got != want {
+		t.Errorf("wrong console mode %#x; want %#x", got, want)
+	}
+	if console.outputCP != CP_UTF8 {
+		t.Errorf("wrong output code page %d", console.outputCP)
+	}
+	if !stream.IsTerminal() {
+		t.Error("stream is not a terminal")
+	}
+	if got := stream.getColorDepth(f); got != ColorTrue {
+		t.Errorf("wrong color depth %s", got)
+	}
+	if got, want := stream.Columns(), 120; got != want {
+		t.Errorf("wrong columns %d; want %d", got, want)
+	}
+	if got, want := stream.Rows(), 40; got != want {
+		t.Errorf("wrong rows %d; want %d", got, want)
+	}
+
+	streams := &Streams{Stdout: stream, Stderr: &OutputStream{}, Stdin: &InputStream{}}
+	if err := streams.Restore(); err != nil {
+		t.Fatalf("unexpected error restoring: %s", err)
+	}
+	if got, want := console.mode, enableProcessedOutput|enableWrapAtEOLOutput; got != want {
+		t.Errorf("wrong console mode after restore %#x; want %#x", got, want)
+	}
+}
+
+func TestConfigureConsoleOutput_alreadyEnabled(t *testing.T) {
+	f := testConsoleFile(t)
+	defer f.Close()
+	console := &fakeConsole{
+		handle:   f.Fd(),
+		terminal: true,
+		mode:     enableProcessedOutput | enableVirtualTerminalProcessing,
+	}
+
+	stream, err := configureConsoleOutput(console, f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stream.restore != nil {
+		t.Error("restore is set, but there is nothing to restore")
+	}
+	if got := stream.getColorDepth(f); got != ColorTrue {
+		t.Errorf("wrong color depth %s", got)
+	}
+}
+
+func TestConfigureConsoleOutput_noVirtualTerminal(t *testing.T) {
+	f := testConsoleFile(t)
+	defer f.Close()
+	console := &fakeConsole{
+		handle:     f.Fd(),
+		terminal:   true,
+		mode:       enableProcessedOutput,
+		setModeErr: errors.New("invalid parameter"),
+	}
+
+	stream, err := configureConsoleOutput(console, f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := console.mode, enableProcessedOutput; got != want {
+		t.Errorf("wrong console mode %#x; want %#x", got, want)
+	}
+	if stream.restore != nil {
+		t.Error("restore is set, but the mode was never changed")
+	}
+	if !stream.IsTerminal() {
+		t.Error("stream is not a terminal")
+	}
+	if got := stream.getColorDepth(f); got != ColorNone {
+		t.Errorf("wrong color depth %s", got)
+	}
+}
+
+func TestConfigureConsoleOutput_notConsole(t *testing.T) {
+	tests := map[string]struct {
+		cygwin       bool
+		wantTerminal bool
+	}{
+		"redirected": {},
+		"cygwin":     {cygwin: true, wantTerminal: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := testConsoleFile(t)
+			defer f.Close()
+			console := &fakeConsole{handle: f.Fd(), cygwin: test.cygwin}
+
+			stream, err := configureConsoleOutput(console, f)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := stream.IsTerminal(); got != test.wantTerminal {
+				t.Errorf("wrong IsTerminal %t; want %t", got, test.wantTerminal)
+			}
+			if console.outputCP != 0 {
+				t.Errorf("code page changed to %d", console.outputCP)
+			}
+			if stream.restore != nil {
+				t.Error("restore is set, but the mode was never changed")
+			}
+		})
+	}
+}
+
+func TestConfigureConsoleInput(t *testing.T) {
+	f := testConsoleFile(t)
+	defer f.Close()
+	console := &fakeConsole{handle: f.Fd(), terminal: true}
+
+	stream, err := configureConsoleInput(console, f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if console.inputCP != CP_UTF8 {
+		t.Errorf("wrong input code page %d", console.inputCP)
+	}
+	if !stream.IsTerminal() {
+		t.Error("stream is not a terminal")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !zos && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!zos,!windows
+
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func disableEchoPlatform(f *os.File) (func() error, error) {
+	return nil, fmt.Errorf("not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,26 @@
+//go:build windows
+// +build windows
+
+package terminal
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// disableEchoPlatform turns off only echoing, leaving the console in line
+// input mode, so that a read that is still in progress when echo is
+// restored completes normally.
+func disableEchoPlatform(f *os.File) (func() error, error) {
+	handle := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return nil, err
+	}
+	if err := windows.SetConsoleMode(handle, mode&^windows.ENABLE_ECHO_INPUT); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return windows.SetConsoleMode(handle, mode)
+	}, nil
+}
@@ -1,3 +1,6 @@
+//go:build !windows
+// +build !windows
+
 package terminal
 
 import (
@@ -10,6 +13,7 @@ func configureOutputHandle(f *os.File) (*OutputStream, error) {
 		File:       f,
 		isTerminal: isTerminalGolangXTerm,
 		getColumns: getColumnsGolangXTerm,
+		getRows:    getRowsGolangXTerm,
 	}, nil
 }
 
@@ -31,3 +35,11 @@ func getColumnsGolangXTerm(f *os.File) int {
 	}
 	return width
 }
+
+func getRowsGolangXTerm(f *os.File) int {
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return defaultRows
+	}
+	return height
+}
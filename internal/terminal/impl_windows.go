@@ -1,35 +1,78 @@
+//go:build windows
+// +build windows
+
 package terminal
 
 import (
-	"fmt"
 	"github.com/mattn/go-isatty"
 	"golang.org/x/sys/windows"
 	"os"
 )
 
+var (
+	kernel32               = windows.NewLazySystemDLL("kernel32.dll")
+	procSetConsoleCP       = kernel32.NewProc("SetConsoleCP")
+	procSetConsoleOutputCP = kernel32.NewProc("SetConsoleOutputCP")
+)
+
 func configureOutputHandle(f *os.File) (*OutputStream, error) {
-	ret := &OutputStream{
-		File: f,
+	return configureConsoleOutput(windowsConsole{}, f)
+}
+
+func configureInputHandle(f *os.File) (*InputStream, error) {
+	return configureConsoleInput(windowsConsole{}, f)
+}
+
+func SetConsoleCP(codepage uint32) error {
+	if r, _, err := procSetConsoleCP.Call(uintptr(codepage)); r == 0 {
+		return err
 	}
-	if fd := f.Fd(); isatty.IsTerminal(fd) {
-		err := SetConsoleOutputCP(CP_UTF8)
-		if err != nil {
-			return nil, fmt.Errorf("failed to set the console to UTF-8 mode; you may need to use a newer version of Windows: %s", err)
-		}
-
-		ret.getColumns = getColumnsWindowsConsole
-		var mode uint32
-		err = windows.SetConsoleMode(windows.Handle(fd), mode)
-		if err != nil {
-			return ret, nil
-		}
-
-	} else if isatty.IsCygwinTerminal(fd) {
-		ret.isTerminal = staticTrue
-		return ret, nil
+	return nil
+}
+
+func SetConsoleOutputCP(codepage uint32) error {
+	if r, _, err := procSetConsoleOutputCP.Call(uintptr(codepage)); r == 0 {
+		return err
 	}
+	return nil
+}
+
+type windowsConsole struct{}
+
+var _ consoleAPI = windowsConsole{}
 
-	return ret, nil
+func (windowsConsole) IsTerminal(handle uintptr) bool {
+	return isatty.IsTerminal(handle)
 }
 
-const CP_UTF8 = 65001
+func (windowsConsole) IsCygwinTerminal(handle uintptr) bool {
+	return isatty.IsCygwinTerminal(handle)
+}
+
+func (windowsConsole) GetConsoleMode(handle uintptr) (uint32, error) {
+	var mode uint32
+	err := windows.GetConsoleMode(windows.Handle(handle), &mode)
+	return mode, err
+}
+
+func (windowsConsole) SetConsoleMode(handle uintptr, mode uint32) error {
+	return windows.SetConsoleMode(windows.Handle(handle), mode)
+}
+
+func (windowsConsole) SetConsoleCP(codepage uint32) error {
+	return SetConsoleCP(codepage)
+}
+
+func (windowsConsole) SetConsoleOutputCP(codepage uint32) error {
+	return SetConsoleOutputCP(codepage)
+}
+
+func (windowsConsole) ConsoleSize(handle uintptr) (columns, rows int, err error) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(handle), &info); err != nil {
+		return 0, 0, err
+	}
+	columns = int(info.Window.Right - info.Window.Left + 1)
+	rows = int(info.Window.Bottom - info.Window.Top + 1)
+	return columns, rows, nil
+}
@@ -0,0 +1,66 @@
+package terminal
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+)
+
+const (
+	envPager     = "PAGER"
+	defaultPager = "less -R"
+)
+
+// Page writes text to stdout. If enabled is set, stdout is a terminal and
+// the text is too tall to fit on it, the text is shown through the command
+// in the PAGER environment variable instead. Setting PAGER to an empty
+// string disables paging, and if the pager can't be started the text is
+// written directly.
+func (s *Streams) Page(text string, enabled bool) error {
+	if !enabled || !s.Stdout.IsTerminal() || s.Stdout.displayLines(text) < s.Stdout.Rows() {
+		return s.writeUnpaged(text)
+	}
+
+	command, ok := os.LookupEnv(envPager)
+	if !ok {
+		command = defaultPager
+	}
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return s.writeUnpaged(text)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = s.Stdout.File
+	cmd.Stderr = s.Stderr.File
+
+	// The pager handles interrupts itself, typically by ignoring them, and
+	// we must not exit and leave it running without us.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+
+	if err := cmd.Start(); err != nil {
+		return s.writeUnpaged(text)
+	}
+	return cmd.Wait()
+}
+
+func (s *Streams) writeUnpaged(text string) error {
+	_, err := io.WriteString(s.Stdout.File, text)
+	return err
+}
+
+// displayLines counts the lines text occupies on the stream, including
+// those added where long lines wrap.
+func (s *OutputStream) displayLines(text string) int {
+	columns := s.Columns()
+	lines := 0
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		lines += displayRows(StringWidth(line), columns)
+	}
+	return lines
+}
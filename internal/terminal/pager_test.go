@@ -0,0 +1,91 @@
+package terminal
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip("sed is needed as the pager")
+	}
+	defer func(old string, ok bool) {
+		if ok {
+			os.Setenv(envPager, old)
+		} else {
+			os.Unsetenv(envPager)
+		}
+	}(os.LookupEnv(envPager))
+	os.Setenv(envPager, "sed s/^/paged:/")
+
+	tall := strings.Repeat("line\n", 5)
+
+	tests := map[string]struct {
+		term    TestTerminal
+		text    string
+		enabled bool
+		want    string
+	}{
+		"fits on the terminal": {
+			term:    TestTerminal{StdoutIsTerminal: true, Rows: 10},
+			text:    tall,
+			enabled: true,
+			want:    tall,
+		},
+		"too tall": {
+			term:    TestTerminal{StdoutIsTerminal: true, Rows: 3},
+			text:    tall,
+			enabled: true,
+			want:    strings.Repeat("paged:line\n", 5),
+		},
+		"disabled": {
+			term: TestTerminal{StdoutIsTerminal: true, Rows: 3},
+			text: tall,
+			want: tall,
+		},
+		"not a terminal": {
+			term:    TestTerminal{Rows: 3},
+			text:    tall,
+			enabled: true,
+			want:    tall,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			streams, close := StreamsForTesting(t, test.term)
+			if err := streams.Page(test.text, test.enabled); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := close(t).Stdout(); got != test.want {
+				t.Errorf("wrong output\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOutputStreamDisplayLines(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		want int
+	}{
+		"empty":                {"", 1},
+		"short lines":          {"one\ntwo\n", 2},
+		"exactly the width":    {"0123456789\n", 1},
+		"wrapped":              {"0123456789012345678901\n", 3},
+		"wide characters":      {"日本語の文章です\n", 2},
+		"escapes have no size": {"\x1b[1m0123456789\x1b[0m\n", 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			streams, close := StreamsForTesting(t, TestTerminal{StdoutIsTerminal: true, Columns: 10})
+			defer close(t)
+			if got := streams.Stdout.displayLines(test.text); got != test.want {
+				t.Errorf("wrong result %d; want %d", got, test.want)
+			}
+		})
+	}
+}
@@ -4,20 +4,45 @@ import (
 	"os"
 )
 
+// ReinitInsidePanicwrap is Init for the child process that panicwrap
+// starts, whose stderr is a pipe to the parent rather than the terminal.
+// The state that the parent recorded before starting the child stands in
+// for what the child can't query itself.
+//
+// The size of that stderr terminal is only recorded once, so it would go
+// stale if the terminal were resized. panicwrap passes stdout straight
+// through, so if stdout is also a terminal, it is assumed to be the same
+// one and its size is used instead. Otherwise the recorded size is used
+// for the rest of the run, and WatchResize never reports stderr resizes.
 func ReinitInsidePanicwrap(state *PrePanicwrapState) (*Streams, error) {
 	ret, err := Init()
 	if err != nil {
 		return ret, err
 	}
 	if state != nil {
+		stdout := ret.Stdout
+		liveSize := state.StderrIsTerminal && stdout.IsTerminal()
 		ret.Stderr = &OutputStream{
 			File: ret.Stderr.File,
 			isTerminal: func(f *os.File) bool {
 				return state.StderrIsTerminal
 			},
 			getColumns: func(f *os.File) int {
+				if liveSize {
+					return stdout.Columns()
+				}
 				return state.StderrWidth
 			},
+			getRows: func(f *os.File) int {
+				if liveSize {
+					return stdout.Rows()
+				}
+				return state.StderrHeight
+			},
+			getColorDepth: func(f *os.File) ColorDepth {
+				return state.StderrColorDepth
+			},
+			restore: ret.Stderr.restore,
 		}
 	}
 	return ret, nil
@@ -26,4 +51,15 @@ func ReinitInsidePanicwrap(state *PrePanicwrapState) (*Streams, error) {
 type PrePanicwrapState struct {
 	StderrIsTerminal bool
 	StderrWidth      int
+	StderrHeight     int
+	StderrColorDepth ColorDepth
+}
+
+func (s *Streams) StateForAfterPanicWrap() *PrePanicwrapState {
+	return &PrePanicwrapState{
+		StderrIsTerminal: s.Stderr.IsTerminal(),
+		StderrWidth:      s.Stderr.Columns(),
+		StderrHeight:     s.Stderr.Rows(),
+		StderrColorDepth: s.Stderr.ColorDepth(),
+	}
 }
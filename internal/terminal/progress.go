@@ -0,0 +1,295 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressRedrawInterval = 100 * time.Millisecond
+	progressReportInterval = 5 * time.Second
+
+	minProgressBarWidth = 10
+	maxProgressBarWidth = 40
+)
+
+var spinnerFrames = []string{"|", "/", "-", `\`}
+
+// Progress shows a stack of progress bars and spinners, one line each, for
+// tasks that may run concurrently.
+//
+// On a terminal the lines are redrawn in place. On any other stream, such
+// as a CI log, each task instead prints a status line when it starts, at
+// most every few seconds while it makes progress, and when it finishes.
+// Nothing is shown at all when running in automation.
+type Progress struct {
+	stream   *OutputStream
+	mode     progressMode
+	interval time.Duration
+
+	mu    sync.Mutex
+	tasks []*ProgressTask
+	frame int
+
+	// drawnWidths holds the display width of each line drawn last time.
+	drawnWidths []int
+
+	resize     <-chan ResizeEvent
+	stopResize func()
+	stop       chan struct{}
+	stopped    chan struct{}
+	stopOnce   sync.Once
+}
+
+type progressMode int
+
+const (
+	progressInPlace progressMode = iota
+	progressReport
+	progressOff
+)
+
+type ProgressOptions struct {
+	// RunningInAutomation turns progress off entirely, even on a terminal,
+	// since whoever is driving Terraform is not watching it.
+	RunningInAutomation bool
+}
+
+// ProgressTask is a single line of a Progress: a progress bar if it was
+// created with a total, or a spinner otherwise.
+type ProgressTask struct {
+	progress *Progress
+	label    string
+	total    int64
+	current  int64
+	started  time.Time
+	done     bool
+	reported string
+}
+
+// NewProgress starts drawing progress on the stdout stream of the given
+// streams, redrawing straight away whenever the terminal is resized. Stop
+// must be called once all of the tasks are done.
+func NewProgress(streams *Streams, opts ProgressOptions) *Progress {
+	p := &Progress{
+		stream:     streams.Stdout,
+		mode:       progressReport,
+		interval:   progressReportInterval,
+		stopResize: func() {},
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	switch {
+	case opts.RunningInAutomation:
+		p.mode = progressOff
+		close(p.stopped)
+		return p
+	case p.stream.IsTerminal():
+		p.mode = progressInPlace
+		p.interval = progressRedrawInterval
+		p.resize, p.stopResize = streams.WatchResize()
+	}
+	go p.run()
+	return p
+}
+
+// AddBar adds a progress bar for a task whose total size is known in
+// advance, such as a download with a known length.
+func (p *Progress) AddBar(label string, total int64) *ProgressTask {
+	return p.add(label, total)
+}
+
+// AddSpinner adds a spinner for a task whose size is not known.
+func (p *Progress) AddSpinner(label string) *ProgressTask {
+	return p.add(label, 0)
+}
+
+func (p *Progress) add(label string, total int64) *ProgressTask {
+	t := &ProgressTask{
+		progress: p,
+		label:    label,
+		total:    total,
+		started:  time.Now(),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tasks = append(p.tasks, t)
+	if p.mode == progressReport {
+		p.report(t)
+	}
+	return t
+}
+
+// Stop draws the final state of every task and stops redrawing.
+func (p *Progress) Stop() {
+	p.stopOnce.Do(func() { close(p.stop) })
+	<-p.stopped
+	p.stopResize()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.mode == progressInPlace {
+		p.redraw()
+	}
+}
+
+func (p *Progress) run() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-p.resize:
+			p.mu.Lock()
+			p.redraw()
+			p.mu.Unlock()
+		case <-ticker.C:
+			p.mu.Lock()
+			p.frame++
+			if p.mode == progressInPlace {
+				p.redraw()
+			} else {
+				for _, t := range p.tasks {
+					if !t.done {
+						p.report(t)
+					}
+				}
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// redraw moves the cursor back up over the lines drawn last time and
+// replaces them. Lines are kept narrower than the terminal so that none of
+// them wrap, which would throw off the count of lines to move back over.
+//
+// If the terminal has since been made narrower, the lines drawn last time
+// may have been reflowed onto several rows each, so the cursor is moved up
+// over as many rows as they now take and everything below is cleared.
+func (p *Progress) redraw() {
+	columns := p.stream.Columns()
+
+	var b strings.Builder
+	rows := 0
+	for _, w := range p.drawnWidths {
+		rows += displayRows(w, columns)
+	}
+	if rows > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", rows)
+	}
+	b.WriteString("\r\x1b[J")
+
+	width := columns - 1
+	p.drawnWidths = p.drawnWidths[:0]
+	for _, t := range p.tasks {
+		line := truncateWidth(t.render(width, p.frame), width)
+		b.WriteString(line)
+		b.WriteByte('\n')
+		p.drawnWidths = append(p.drawnWidths, StringWidth(line))
+	}
+	io.WriteString(p.stream.File, b.String())
+}
+
+// displayRows returns how many rows a line of the given display width
+// takes up on a terminal with the given number of columns.
+func displayRows(width, columns int) int {
+	if width <= columns || columns <= 0 {
+		return 1
+	}
+	return (width + columns - 1) / columns
+}
+
+func (p *Progress) report(t *ProgressTask) {
+	line := t.status()
+	if line == t.reported {
+		return
+	}
+	t.reported = line
+	fmt.Fprintln(p.stream.File, line)
+}
+
+// Add records that n more units of the task's total are complete.
+func (t *ProgressTask) Add(n int64) {
+	t.progress.mu.Lock()
+	defer t.progress.mu.Unlock()
+	t.current += n
+}
+
+// Write counts the bytes written towards the task's total, so that a task
+// can be passed to io.TeeReader or io.MultiWriter alongside a download.
+func (t *ProgressTask) Write(b []byte) (int, error) {
+	t.Add(int64(len(b)))
+	return len(b), nil
+}
+
+// Done marks the task as finished.
+func (t *ProgressTask) Done() {
+	p := t.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t.done {
+		return
+	}
+	t.done = true
+	if t.total > 0 {
+		t.current = t.total
+	}
+	if p.mode == progressReport {
+		p.report(t)
+	}
+}
+
+func (t *ProgressTask) percent() int64 {
+	if t.current >= t.total {
+		return 100
+	}
+	return t.current * 100 / t.total
+}
+
+func (t *ProgressTask) render(width, frame int) string {
+	if t.total <= 0 {
+		if t.done {
+			return t.label + " done"
+		}
+		return t.label + " " + spinnerFrames[frame%len(spinnerFrames)]
+	}
+
+	suffix := fmt.Sprintf(" %3d%%", t.percent())
+	barWidth := width - StringWidth(t.label) - len(" []") - len(suffix)
+	if barWidth > maxProgressBarWidth {
+		barWidth = maxProgressBarWidth
+	}
+	if barWidth < minProgressBarWidth {
+		return t.label + suffix
+	}
+
+	filled := int(int64(barWidth) * t.percent() / 100)
+	bar := strings.Repeat("=", filled)
+	if filled < barWidth {
+		bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+	}
+	return fmt.Sprintf("%s [%s]%s", t.label, bar, suffix)
+}
+
+func (t *ProgressTask) status() string {
+	switch {
+	case t.total > 0:
+		return fmt.Sprintf("%s: %d%%", t.label, t.percent())
+	case t.done:
+		return fmt.Sprintf("%s: done", t.label)
+	default:
+		elapsed := time.Since(t.started) / progressReportInterval * progressReportInterval
+		if elapsed == 0 {
+			return fmt.Sprintf("%s...", t.label)
+		}
+		return fmt.Sprintf("%s: still working (%s elapsed)", t.label, elapsed)
+	}
+}
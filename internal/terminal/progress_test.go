@@ -0,0 +1,136 @@
+package terminal
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestProgress_notTerminal(t *testing.T) {
+	streams, close := StreamsForTesting(t, TestTerminal{})
+
+	p := NewProgress(streams, ProgressOptions{})
+	download := p.AddBar("Downloading", 200)
+	verify := p.AddSpinner("Verifying")
+	download.Write(make([]byte, 50))
+	download.Done()
+	verify.Done()
+	p.Stop()
+
+	want := strings.Join([]string{
+		"Downloading: 0%",
+		"Verifying...",
+		"Downloading: 100%",
+		"Verifying: done",
+		"",
+	}, "\n")
+	if got := close(t).Stdout(); got != want {
+		t.Errorf("wrong output\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestProgress_automation(t *testing.T) {
+	for _, term := range []TestTerminal{{StdoutIsTerminal: true}, {}} {
+		streams, close := StreamsForTesting(t, term)
+
+		p := NewProgress(streams, ProgressOptions{RunningInAutomation: true})
+		task := p.AddBar("Downloading", 100)
+		task.Add(50)
+		task.Done()
+		p.Stop()
+
+		if got := close(t).All(); got != "" {
+			t.Errorf("unexpected output with %#v: %q", term, got)
+		}
+	}
+}
+
+func TestProgress_redraw(t *testing.T) {
+	streams, close := StreamsForTesting(t, TestTerminal{StdoutIsTerminal: true, Columns: 40})
+
+	// The progress is put together by hand so that its redraws aren't
+	// driven by a ticker.
+	p := &Progress{stream: streams.Stdout}
+	bar := p.add("mod", 100)
+	p.add("日本語のプロバイダをダウンロード中", 0)
+	bar.Add(50)
+
+	p.redraw()
+	first := "\r\x1b[J" +
+		"mod [==============>             ]  50%\n" +
+		"日本語のプロバイダをダウンロード中 |\n"
+	p.redraw()
+	second := "\x1b[2A\r\x1b[J" +
+		"mod [==============>             ]  50%\n" +
+		"日本語のプロバイダをダウンロード中 |\n"
+
+	// Narrowing the terminal reflows both lines onto two rows each, so
+	// the next redraw must move up over four.
+	p.stream.getColumns = func(*os.File) int { return 30 }
+	p.redraw()
+	third := "\x1b[4A\r\x1b[J" +
+		"mod [=========>        ]  50%\n" +
+		"日本語のプロバイダをダウンロ…\n"
+
+	if got, want := close(t).Stdout(), first+second+third; got != want {
+		t.Errorf("wrong output\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestProgressTaskRender(t *testing.T) {
+	tests := map[string]struct {
+		label   string
+		total   int64
+		current int64
+		done    bool
+		width   int
+		want    string
+	}{
+		"bar": {
+			label:   "aws",
+			total:   10,
+			current: 3,
+			width:   30,
+			want:    "aws [=====>             ]  30%",
+		},
+		"bar with a wide label": {
+			label:   "ダウンロード",
+			total:   10,
+			current: 10,
+			width:   30,
+			want:    "ダウンロード [==========] 100%",
+		},
+		"too narrow for a bar": {
+			label:   "ダウンロード",
+			total:   10,
+			current: 5,
+			width:   20,
+			want:    "ダウンロード  50%",
+		},
+		"spinner": {
+			label: "aws",
+			width: 30,
+			want:  "aws |",
+		},
+		"finished spinner": {
+			label: "aws",
+			done:  true,
+			width: 30,
+			want:  "aws done",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			task := &ProgressTask{
+				label:   test.label,
+				total:   test.total,
+				current: test.current,
+				done:    test.done,
+			}
+			if got := task.render(test.width, 0); got != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// ErrPromptInterrupted is returned when the user interrupts a prompt
+// before answering it.
+var ErrPromptInterrupted = errors.New("interrupted")
+
+// PromptUnavailableError is returned by the prompt functions when they
+// refuse to ask the user anything, because nobody could answer.
+type PromptUnavailableError struct {
+	Reason string
+}
+
+func (e *PromptUnavailableError) Error() string {
+	return fmt.Sprintf("cannot prompt for input: %s", e.Reason)
+}
+
+type PromptOptions struct {
+	// RunningInAutomation disables prompting entirely, even if stdin is a
+	// terminal, since whoever is driving Terraform is not watching it.
+	RunningInAutomation bool
+}
+
+// Prompt writes query to out and returns the line the user types in
+// response, without its line ending.
+func (s *InputStream) Prompt(out *OutputStream, query string, opts PromptOptions) (string, error) {
+	if err := s.checkPrompt(opts); err != nil {
+		return "", err
+	}
+	fmt.Fprint(out.File, query+" ")
+	return s.readInterruptible()
+}
+
+// Confirm asks a yes or no question, repeating it until the user gives one
+// of those answers.
+func (s *InputStream) Confirm(out *OutputStream, query string, opts PromptOptions) (bool, error) {
+	for {
+		answer, err := s.Prompt(out, query+" (yes/no)", opts)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "yes", "y":
+			return true, nil
+		case "no", "n":
+			return false, nil
+		}
+		fmt.Fprintln(out.File, `Please answer "yes" or "no".`)
+	}
+}
+
+// PromptSecret is like Prompt but stops the terminal from echoing what the
+// user types.
+func (s *InputStream) PromptSecret(out *OutputStream, query string, opts PromptOptions) (string, error) {
+	if err := s.checkPrompt(opts); err != nil {
+		return "", err
+	}
+
+	disableEcho := s.disableEcho
+	if disableEcho == nil {
+		disableEcho = disableEchoPlatform
+	}
+	restore, err := disableEcho(s.File)
+	if err != nil {
+		return "", fmt.Errorf("cannot hide input: %s", err)
+	}
+	fmt.Fprint(out.File, query+" ")
+	ret, err := s.readInterruptible()
+	if rErr := restore(); rErr != nil && err == nil {
+		err = rErr
+	}
+	fmt.Fprintln(out.File)
+	return ret, err
+}
+
+func (s *InputStream) checkPrompt(opts PromptOptions) error {
+	if opts.RunningInAutomation {
+		return &PromptUnavailableError{Reason: "running in automation"}
+	}
+	if !s.IsTerminal() {
+		return &PromptUnavailableError{Reason: "standard input is not a terminal"}
+	}
+	return nil
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// readInterruptible waits for the next line from the stream, returning
+// early if the user interrupts. The read itself can't be cancelled, so an
+// interrupted read carries on in the background and its line is returned
+// to the next prompt instead of being lost.
+func (s *InputStream) readInterruptible() (string, error) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+
+	s.readMu.Lock()
+	if s.pending == nil {
+		ch := make(chan lineResult, 1)
+		go func() {
+			line, err := s.readLine()
+			ch <- lineResult{line, err}
+		}()
+		s.pending = ch
+	}
+	pending := s.pending
+	s.readMu.Unlock()
+
+	select {
+	case r := <-pending:
+		s.readMu.Lock()
+		s.pending = nil
+		s.readMu.Unlock()
+		return r.line, r.err
+	case <-sigCh:
+		return "", ErrPromptInterrupted
+	}
+}
+
+// readLine reads a byte at a time so that nothing after the line ending is
+// consumed, which would otherwise be lost to the next prompt.
+func (s *InputStream) readLine() (string, error) {
+	var line []byte
+	var buf [1]byte
+	for {
+		n, err := s.File.Read(buf[:])
+		if n > 0 {
+			if buf[0] == '\n' {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF && len(line) > 0 {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
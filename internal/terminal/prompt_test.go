@@ -0,0 +1,104 @@
+package terminal
+
+import (
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	streams, close := StreamsForTesting(t, TestTerminal{
+		StdinIsTerminal: true,
+		Input:           "first answer\r\nsecond answer\nlast",
+	})
+
+	for _, want := range []string{"first answer", "second answer", "last"} {
+		got, err := streams.Stdin.Prompt(streams.Stdout, "Value?", PromptOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got != want {
+			t.Errorf("wrong answer %q; want %q", got, want)
+		}
+	}
+
+	out := close(t)
+	if got, want := out.Stdout(), "Value? Value? Value? "; got != want {
+		t.Errorf("wrong output %q; want %q", got, want)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	streams, close := StreamsForTesting(t, TestTerminal{
+		StdinIsTerminal: true,
+		Input:           "maybe\n YES \nn\n",
+	})
+
+	for _, want := range []bool{true, false} {
+		got, err := streams.Stdin.Confirm(streams.Stdout, "Continue?", PromptOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got != want {
+			t.Errorf("wrong answer %t; want %t", got, want)
+		}
+	}
+
+	out := close(t)
+	want := "Continue? (yes/no) Please answer \"yes\" or \"no\".\nContinue? (yes/no) Continue? (yes/no) "
+	if got := out.Stdout(); got != want {
+		t.Errorf("wrong output %q; want %q", got, want)
+	}
+}
+
+func TestPromptSecret(t *testing.T) {
+	streams, close := StreamsForTesting(t, TestTerminal{
+		StdinIsTerminal: true,
+		Input:           "hunter2\n",
+	})
+
+	got, err := streams.Stdin.PromptSecret(streams.Stdout, "Password:", PromptOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != "hunter2" {
+		t.Errorf("wrong answer %q", got)
+	}
+
+	out := close(t)
+	if got, want := out.Stdout(), "Password: \n"; got != want {
+		t.Errorf("wrong output %q; want %q", got, want)
+	}
+}
+
+func TestPrompt_unavailable(t *testing.T) {
+	tests := map[string]struct {
+		term TestTerminal
+		opts PromptOptions
+		want string
+	}{
+		"stdin is not a terminal": {
+			term: TestTerminal{Input: "yes\n"},
+			want: "cannot prompt for input: standard input is not a terminal",
+		},
+		"running in automation": {
+			term: TestTerminal{StdinIsTerminal: true, Input: "yes\n"},
+			opts: PromptOptions{RunningInAutomation: true},
+			want: "cannot prompt for input: running in automation",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			streams, close := StreamsForTesting(t, test.term)
+			_, err := streams.Stdin.Prompt(streams.Stdout, "Value?", test.opts)
+			if _, ok := err.(*PromptUnavailableError); !ok {
+				t.Fatalf("wrong error %#v", err)
+			}
+			if got := err.Error(); got != test.want {
+				t.Errorf("wrong error %q; want %q", got, test.want)
+			}
+			if out := close(t); out.All() != "" {
+				t.Errorf("unexpected output %q", out.All())
+			}
+		})
+	}
+}
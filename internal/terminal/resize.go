@@ -0,0 +1,49 @@
+package terminal
+
+import "sync"
+
+// ResizeEvent reports the widths of the output streams after the terminal
+// they are attached to has been resized.
+type ResizeEvent struct {
+	StdoutColumns int
+	StderrColumns int
+}
+
+// WatchResize returns a channel that receives a ResizeEvent each time the
+// width of either output stream changes, and a function that stops
+// watching and closes the channel. Only the most recent event is kept if
+// the receiver falls behind, since renderers only care about the current
+// size.
+func (s *Streams) WatchResize() (<-chan ResizeEvent, func()) {
+	ch := make(chan ResizeEvent, 1)
+	stop := make(chan struct{})
+
+	last := s.resizeEvent()
+	go func() {
+		defer close(ch)
+		watchResize(stop, func() {
+			ev := s.resizeEvent()
+			if ev == last {
+				return
+			}
+			last = ev
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- ev
+		})
+	}()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { close(stop) })
+	}
+}
+
+func (s *Streams) resizeEvent() ResizeEvent {
+	return ResizeEvent{
+		StdoutColumns: s.Stdout.Columns(),
+		StderrColumns: s.Stderr.Columns(),
+	}
+}
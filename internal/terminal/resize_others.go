@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package terminal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func watchResize(stop <-chan struct{}, changed func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-sigCh:
+			changed()
+		}
+	}
+}
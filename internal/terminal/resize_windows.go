@@ -0,0 +1,25 @@
+//go:build windows
+// +build windows
+
+package terminal
+
+import "time"
+
+const resizePollInterval = 250 * time.Millisecond
+
+// The Windows console reports resizes as WINDOW_BUFFER_SIZE_EVENT records
+// in the input buffer, but reading them would also consume the user's
+// keystrokes, so we poll the screen buffer size instead.
+func watchResize(stop <-chan struct{}, changed func()) {
+	ticker := time.NewTicker(resizePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			changed()
+		}
+	}
+}
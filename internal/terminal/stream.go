@@ -1,14 +1,21 @@
 package terminal
 
-import "os"
+import (
+	"os"
+	"sync"
+)
 
 const defaultColumns int = 78
+const defaultRows int = 24
 const defaultIsTerminal bool = false
 
 type OutputStream struct {
-	File       *os.File
-	isTerminal func(file *os.File) bool
-	getColumns func(file *os.File) int
+	File          *os.File
+	isTerminal    func(file *os.File) bool
+	getColumns    func(file *os.File) int
+	getRows       func(file *os.File) int
+	getColorDepth func(file *os.File) ColorDepth
+	restore       func() error
 }
 
 func (s *OutputStream) Columns() int {
@@ -18,6 +25,18 @@ func (s *OutputStream) Columns() int {
 	return s.getColumns(s.File)
 }
 
+func (s *OutputStream) Rows() int {
+	if s.getRows == nil {
+		return defaultRows
+	}
+	return s.getRows(s.File)
+}
+
+// Size returns the width and height of the stream, as Columns and Rows.
+func (s *OutputStream) Size() (columns, rows int) {
+	return s.Columns(), s.Rows()
+}
+
 func (s *OutputStream) IsTerminal() bool {
 	if s.isTerminal == nil {
 		return defaultIsTerminal
@@ -26,8 +45,16 @@ func (s *OutputStream) IsTerminal() bool {
 }
 
 type InputStream struct {
-	File       *os.File
-	isTerminal func(file *os.File) bool
+	File        *os.File
+	isTerminal  func(file *os.File) bool
+	disableEcho func(file *os.File) (restore func() error, err error)
+	restore     func() error
+
+	// pending is the result of the line being read from File, if any. A
+	// read that a prompt stopped waiting for is left for the next prompt,
+	// so that only one read is ever in flight.
+	readMu  sync.Mutex
+	pending chan lineResult
 }
 
 func (s *InputStream) IsTerminal() bool {
@@ -34,6 +34,21 @@ func Init() (*Streams, error) {
 	}, nil
 }
 
+// Restore undoes any changes Init made to the terminal's settings, and
+// should be called before the program exits.
+func (s *Streams) Restore() error {
+	var firstErr error
+	for _, restore := range []func() error{s.Stdout.restore, s.Stderr.restore, s.Stdin.restore} {
+		if restore == nil {
+			continue
+		}
+		if err := restore(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 func (s *Streams) Print(a ...interface{}) (n int, err error) {
 	return fmt.Fprint(s.Stdout.File, a...)
 }
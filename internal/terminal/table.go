@@ -0,0 +1,151 @@
+package terminal
+
+import "strings"
+
+const (
+	tableColumnGap      = "  "
+	minTableColumnWidth = 6
+)
+
+// Table lays out rows of text in aligned columns.
+//
+// On a terminal, columns are narrowed to fit the stream's width, with
+// cells that no longer fit either truncated or, if Wrap is set, continued
+// on following lines. On any other stream the table is written as
+// tab-separated values, so that it is easy to process with other tools.
+type Table struct {
+	Headers []string
+	Rows    [][]string
+
+	// Wrap continues cells that are too wide for their column on
+	// following lines instead of truncating them.
+	Wrap bool
+}
+
+func NewTable(headers ...string) *Table {
+	return &Table{
+		Headers: headers,
+	}
+}
+
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// Render returns the table laid out for the given stream.
+func (t *Table) Render(stream *OutputStream) string {
+	if !stream.IsTerminal() {
+		return t.renderTSV()
+	}
+
+	widths := t.columnWidths(stream.Columns() - 1)
+	var b strings.Builder
+	if len(t.Headers) > 0 {
+		t.renderRow(&b, t.Headers, widths)
+	}
+	for _, row := range t.Rows {
+		t.renderRow(&b, row, widths)
+	}
+	return b.String()
+}
+
+func (t *Table) renderTSV() string {
+	replacer := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+	var b strings.Builder
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteByte('\t')
+			}
+			b.WriteString(replacer.Replace(ansiEscape.ReplaceAllString(cell, "")))
+		}
+		b.WriteByte('\n')
+	}
+	if len(t.Headers) > 0 {
+		writeRow(t.Headers)
+	}
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// columnWidths returns the width of each column, narrowing the widest
+// column one step at a time until the table fits in maxWidth or every
+// column is down to the minimum width.
+func (t *Table) columnWidths(maxWidth int) []int {
+	var widths []int
+	measure := func(row []string) {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := StringWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	measure(t.Headers)
+	for _, row := range t.Rows {
+		measure(row)
+	}
+	if len(widths) == 0 {
+		return widths
+	}
+
+	total := len(tableColumnGap) * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	for total > maxWidth {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minTableColumnWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+func (t *Table) renderRow(b *strings.Builder, row []string, widths []int) {
+	cells := make([][]string, len(row))
+	lines := 1
+	for i, cell := range row {
+		if t.Wrap {
+			// Wrap can leave lines wider than asked for, such as ones with
+			// leading spaces, so they are truncated to fit all the same.
+			cells[i] = strings.Split(Wrap(cell, widths[i], ""), "\n")
+			for j, line := range cells[i] {
+				cells[i][j] = truncateWidth(line, widths[i])
+			}
+		} else {
+			cells[i] = []string{truncateWidth(cell, widths[i])}
+		}
+		if len(cells[i]) > lines {
+			lines = len(cells[i])
+		}
+	}
+
+	for line := 0; line < lines; line++ {
+		var lineBuf strings.Builder
+		for i, cell := range cells {
+			if i > 0 {
+				lineBuf.WriteString(tableColumnGap)
+			}
+			text := ""
+			if line < len(cell) {
+				text = cell[line]
+			}
+			lineBuf.WriteString(text)
+			lineBuf.WriteString(strings.Repeat(" ", widths[i]-StringWidth(text)))
+		}
+		b.WriteString(strings.TrimRight(lineBuf.String(), " "))
+		b.WriteByte('\n')
+	}
+}
@@ -0,0 +1,122 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableRender(t *testing.T) {
+	tests := map[string]struct {
+		term  TestTerminal
+		table *Table
+		want  []string
+	}{
+		"fits": {
+			term: TestTerminal{StdoutIsTerminal: true, Columns: 80},
+			table: &Table{
+				Headers: []string{"NAME", "VERSION"},
+				Rows: [][]string{
+					{"aws", "3.30.0"},
+					{"kubernetes", "2.0.2"},
+				},
+			},
+			want: []string{
+				"NAME        VERSION",
+				"aws         3.30.0",
+				"kubernetes  2.0.2",
+			},
+		},
+		"truncated": {
+			term: TestTerminal{StdoutIsTerminal: true, Columns: 16},
+			table: &Table{
+				Headers: []string{"NAME", "DESCRIPTION"},
+				Rows: [][]string{
+					{"aws", "Amazon Web Services"},
+				},
+			},
+			want: []string{
+				"NAME  DESCRIPT…",
+				"aws   Amazon W…",
+			},
+		},
+		"wrapped": {
+			term: TestTerminal{StdoutIsTerminal: true, Columns: 16},
+			table: &Table{
+				Headers: []string{"NAME", "DESCRIPTION"},
+				Rows: [][]string{
+					{"aws", "Amazon Web Services"},
+				},
+				Wrap: true,
+			},
+			want: []string{
+				"NAME  DESCRIPTI",
+				"      ON",
+				"aws   Amazon",
+				"      Web",
+				"      Services",
+			},
+		},
+		"wrapped with leading spaces": {
+			term: TestTerminal{StdoutIsTerminal: true, Columns: 16},
+			table: &Table{
+				Headers: []string{"NAME", "DESCRIPTION"},
+				Rows: [][]string{
+					{"aws", "            Amazon Web Services"},
+				},
+				Wrap: true,
+			},
+			want: []string{
+				"NAME  DESCRIPTI",
+				"      ON",
+				"aws           …",
+				"      Amazon",
+				"      Web",
+				"      Services",
+			},
+		},
+		"narrower than the minimum column width": {
+			term: TestTerminal{StdoutIsTerminal: true, Columns: 10},
+			table: &Table{
+				Headers: []string{"NAME", "DESCRIPTION"},
+				Rows: [][]string{
+					{"kubernetes", "Kubernetes"},
+				},
+				Wrap: true,
+			},
+			want: []string{
+				"NAME    DESCRI",
+				"        PTION",
+				"kubern  Kubern",
+				"etes    etes",
+			},
+		},
+		"not a terminal": {
+			term: TestTerminal{Columns: 16},
+			table: &Table{
+				Headers: []string{"NAME", "DESCRIPTION"},
+				Rows: [][]string{
+					{"aws", "Amazon\tWeb\nServices"},
+					{"\x1b[1mgoogle\x1b[0m", "Google Cloud"},
+				},
+			},
+			want: []string{
+				"NAME\tDESCRIPTION",
+				"aws\tAmazon Web Services",
+				"google\tGoogle Cloud",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			streams, close := StreamsForTesting(t, test.term)
+			defer close(t)
+
+			got := test.table.Render(streams.Stdout)
+			want := strings.Join(test.want, "\n") + "\n"
+			if got != want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package terminal
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestTerminal describes the terminal that the streams returned by
+// StreamsForTesting pretend to be attached to.
+type TestTerminal struct {
+	StdoutIsTerminal bool
+	StderrIsTerminal bool
+	StdinIsTerminal  bool
+
+	// Columns and Rows are the size reported by both output streams. Zero
+	// means the same default size as for a stream that is not a terminal.
+	Columns int
+	Rows    int
+
+	// ColorDepth is the color depth that both output streams report while
+	// they are terminals, rather than one detected from TERM and COLORTERM.
+	// NO_COLOR and FORCE_COLOR still take precedence, as they do for a real
+	// terminal.
+	ColorDepth ColorDepth
+
+	// Input is made available on stdin, which then reaches end of file.
+	Input string
+}
+
+// StreamsForTesting returns streams backed by pipes, for tests of code that
+// writes to or reads from a terminal. The returned function must be called
+// once the code under test has finished with the streams, and returns
+// everything it wrote to stdout and stderr.
+func StreamsForTesting(t *testing.T, term TestTerminal) (streams *Streams, close func(*testing.T) *TestOutput) {
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create stdin pipe: %s", err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create stdout pipe: %s", err)
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create stderr pipe: %s", err)
+	}
+
+	go func() {
+		io.WriteString(stdinW, term.Input)
+		stdinW.Close()
+	}()
+
+	output := &TestOutput{}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go output.capture(&wg, stdoutR, false)
+	go output.capture(&wg, stderrR, true)
+
+	getColumns := func(*os.File) int {
+		if term.Columns == 0 {
+			return defaultColumns
+		}
+		return term.Columns
+	}
+	getRows := func(*os.File) int {
+		if term.Rows == 0 {
+			return defaultRows
+		}
+		return term.Rows
+	}
+	getColorDepth := func(*os.File) ColorDepth {
+		return term.ColorDepth
+	}
+	streams = &Streams{
+		Stdout: &OutputStream{
+			File:          stdoutW,
+			isTerminal:    staticIsTerminal(term.StdoutIsTerminal),
+			getColumns:    getColumns,
+			getRows:       getRows,
+			getColorDepth: getColorDepth,
+		},
+		Stderr: &OutputStream{
+			File:          stderrW,
+			isTerminal:    staticIsTerminal(term.StderrIsTerminal),
+			getColumns:    getColumns,
+			getRows:       getRows,
+			getColorDepth: getColorDepth,
+		},
+		Stdin: &InputStream{
+			File:       stdinR,
+			isTerminal: staticIsTerminal(term.StdinIsTerminal),
+			disableEcho: func(*os.File) (func() error, error) {
+				return func() error { return nil }, nil
+			},
+		},
+	}
+
+	close = func(t *testing.T) *TestOutput {
+		if err := stdoutW.Close(); err != nil {
+			t.Errorf("failed to close stdout pipe: %s", err)
+		}
+		if err := stderrW.Close(); err != nil {
+			t.Errorf("failed to close stderr pipe: %s", err)
+		}
+		wg.Wait()
+		stdinR.Close()
+		return output
+	}
+	return streams, close
+}
+
+func staticIsTerminal(v bool) func(*os.File) bool {
+	return func(*os.File) bool {
+		return v
+	}
+}
+
+// TestOutput is the output captured from streams created by
+// StreamsForTesting, keeping the order in which stdout and stderr writes
+// arrived.
+type TestOutput struct {
+	mu    sync.Mutex
+	parts []testOutputPart
+}
+
+type testOutputPart struct {
+	isStderr bool
+	text     string
+}
+
+func (o *TestOutput) capture(wg *sync.WaitGroup, r io.ReadCloser, isStderr bool) {
+	defer wg.Done()
+	defer r.Close()
+
+	buf := make([]byte, 1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			o.mu.Lock()
+			o.parts = append(o.parts, testOutputPart{isStderr: isStderr, text: string(buf[:n])})
+			o.mu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// All returns stdout and stderr interleaved in the order they were
+// received, which is only approximately the order they were written in.
+func (o *TestOutput) All() string {
+	return o.join(func(testOutputPart) bool { return true })
+}
+
+func (o *TestOutput) Stdout() string {
+	return o.join(func(p testOutputPart) bool { return !p.isStderr })
+}
+
+func (o *TestOutput) Stderr() string {
+	return o.join(func(p testOutputPart) bool { return p.isStderr })
+}
+
+func (o *TestOutput) join(include func(testOutputPart) bool) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var b strings.Builder
+	for _, p := range o.parts {
+		if include(p) {
+			b.WriteString(p.text)
+		}
+	}
+	return b.String()
+}
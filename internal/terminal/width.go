@@ -0,0 +1,127 @@
+package terminal
+
+import (
+	"github.com/apparentlymart/go-textseg/v12/textseg"
+	"golang.org/x/text/width"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ansiEscape matches the escape sequences that select colors and move the
+// cursor, which take up no space on the screen.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]")
+
+// StringWidth returns the number of columns s takes up on a terminal.
+//
+// Each grapheme cluster, such as a letter followed by combining marks,
+// counts as a single character. East Asian wide and fullwidth characters
+// take up two columns, and ANSI escape sequences take up none.
+func StringWidth(s string) int {
+	ret := 0
+	forEachCluster(s, func(cluster string, width int) {
+		ret += width
+	})
+	return ret
+}
+
+// forEachCluster calls fn with each grapheme cluster and escape sequence
+// of s in turn, along with the number of columns it takes up.
+func forEachCluster(s string, fn func(cluster string, width int)) {
+	for s != "" {
+		next := len(s)
+		if loc := ansiEscape.FindStringIndex(s); loc != nil {
+			if loc[0] == 0 {
+				fn(s[:loc[1]], 0)
+				s = s[loc[1]:]
+				continue
+			}
+			next = loc[0]
+		}
+
+		text := []byte(s[:next])
+		for len(text) > 0 {
+			advance, cluster, err := textseg.ScanGraphemeClusters(text, true)
+			if err != nil || advance == 0 {
+				// Not expected from a complete buffer, but we must not
+				// loop forever if it happens.
+				advance = len(text)
+				cluster = text
+			}
+			fn(string(cluster), clusterWidth(string(cluster)))
+			text = text[advance:]
+		}
+		s = s[next:]
+	}
+}
+
+func clusterWidth(cluster string) int {
+	r, _ := utf8.DecodeRuneInString(cluster)
+	switch {
+	case unicode.IsControl(r), unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case strings.ContainsRune(cluster, '\uFE0F'):
+		// The emoji variation selector asks for the wide emoji
+		// presentation of a character that is otherwise narrow.
+		return 2
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// truncateWidth shortens s to at most the given number of columns,
+// marking the cut with an ellipsis.
+func truncateWidth(s string, maxWidth int) string {
+	if StringWidth(s) <= maxWidth {
+		return s
+	}
+	if maxWidth <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	used := 0
+	escaped := false
+	forEachCluster(s, func(cluster string, width int) {
+		if width == 0 {
+			if strings.HasPrefix(cluster, "\x1b") {
+				escaped = true
+			}
+			b.WriteString(cluster)
+			return
+		}
+		if used < 0 || used+width > maxWidth-1 {
+			used = -1
+			return
+		}
+		b.WriteString(cluster)
+		used += width
+	})
+	b.WriteString("…")
+	if escaped {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// splitWidth breaks s into pieces of at most the given number of columns.
+func splitWidth(s string, maxWidth int) []string {
+	var ret []string
+	var b strings.Builder
+	used := 0
+	forEachCluster(s, func(cluster string, width int) {
+		if used > 0 && used+width > maxWidth {
+			ret = append(ret, b.String())
+			b.Reset()
+			used = 0
+		}
+		b.WriteString(cluster)
+		used += width
+	})
+	return append(ret, b.String())
+}
@@ -0,0 +1,153 @@
+package terminal
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// noBreakBefore are the characters that Japanese line breaking rules do
+// not allow at the start of a line, so a line is never broken just before
+// one of them.
+const noBreakBefore = "、。，．・：；？！ー々）」』】〉》〕］｝ぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ"
+
+// Wrap breaks each line of text into lines no wider than width columns,
+// as measured by StringWidth, and returns the result. Lines are broken at
+// spaces and between East Asian wide characters, which are not usually
+// separated by spaces, and a word too long to fit on a line of its own is
+// split wherever it must be.
+//
+// Every line after the first one of each line of text is prefixed with
+// indent, to give a hanging indent.
+func Wrap(text string, width int, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width, indent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type wrapTokenKind int
+
+const (
+	wrapWord wrapTokenKind = iota
+	wrapSpace
+	wrapWide
+)
+
+// wrapToken is a run of text that is never broken unless it is too wide
+// to fit on a line by itself.
+type wrapToken struct {
+	kind  wrapTokenKind
+	text  string
+	width int
+}
+
+func wrapLine(line string, width int, indent string) string {
+	indentWidth := StringWidth(indent)
+	if width-indentWidth <= 0 {
+		indent, indentWidth = "", 0
+	}
+	if width <= 0 || StringWidth(line) <= width {
+		return line
+	}
+
+	var b strings.Builder
+	used := 0
+	lineHasContent := false
+	var pendingSpace string
+	newLine := func() {
+		b.WriteByte('\n')
+		b.WriteString(indent)
+		used = indentWidth
+		lineHasContent = false
+		pendingSpace = ""
+	}
+
+	for i, token := range wrapTokens(line) {
+		if token.kind == wrapSpace {
+			if i == 0 {
+				// Leading whitespace is the line's own indentation, so it
+				// is kept as it is. If it fills the whole line, whatever
+				// follows starts on the next one.
+				b.WriteString(token.text)
+				used += token.width
+				lineHasContent = used >= width
+				continue
+			}
+			pendingSpace = token.text
+			continue
+		}
+
+		spaceWidth := StringWidth(pendingSpace)
+		if lineHasContent && used+spaceWidth+token.width > width {
+			newLine()
+			spaceWidth = 0
+		}
+		if lineHasContent {
+			b.WriteString(pendingSpace)
+			used += spaceWidth
+		}
+		pendingSpace = ""
+
+		text := token.text
+		for used+StringWidth(text) > width {
+			pieces := splitWidth(text, width-used)
+			if lineHasContent && StringWidth(pieces[0]) > width-used {
+				// Not even one character fits in what is left of the line.
+				newLine()
+				continue
+			}
+			// A line always gets at least one character, even if that
+			// character alone is too wide for it.
+			b.WriteString(pieces[0])
+			lineHasContent = true
+			text = strings.Join(pieces[1:], "")
+			if text == "" {
+				used += StringWidth(pieces[0])
+				break
+			}
+			newLine()
+		}
+		b.WriteString(text)
+		used += StringWidth(text)
+		lineHasContent = true
+	}
+	return b.String()
+}
+
+// wrapTokens splits a line into runs of spaces, words, and individual
+// wide characters.
+func wrapTokens(line string) []wrapToken {
+	var tokens []wrapToken
+	forEachCluster(line, func(cluster string, width int) {
+		last := len(tokens) - 1
+		r, _ := utf8.DecodeRuneInString(cluster)
+
+		var kind wrapTokenKind
+		switch {
+		case last >= 0 && width == 0:
+			// Escape sequences and stray zero-width characters stay with
+			// whatever they follow.
+			kind = tokens[last].kind
+		case last >= 0 && tokens[last].kind != wrapSpace && strings.ContainsRune(noBreakBefore, r):
+			kind = tokens[last].kind
+		case unicode.IsSpace(r):
+			kind = wrapSpace
+		case width == 2:
+			kind = wrapWide
+		default:
+			kind = wrapWord
+		}
+
+		joins := last >= 0 && tokens[last].kind == kind &&
+			(kind != wrapWide || width < 2 || strings.ContainsRune(noBreakBefore, r))
+		if !joins {
+			tokens = append(tokens, wrapToken{kind: kind})
+			last++
+		}
+		tokens[last].text += cluster
+		tokens[last].width += width
+	})
+	return tokens
+}
@@ -0,0 +1,105 @@
+package terminal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWrap(t *testing.T) {
+	tests := map[string]struct {
+		text   string
+		width  int
+		indent string
+		want   string
+	}{
+		"fits": {
+			text:  "short",
+			width: 10,
+			want:  "short",
+		},
+		"plain": {
+			text:  "the quick brown fox jumps over the lazy dog",
+			width: 15,
+			want:  "the quick brown\nfox jumps over\nthe lazy dog",
+		},
+		"long word": {
+			text:  "supercalifragilistic",
+			width: 8,
+			want:  "supercal\nifragili\nstic",
+		},
+		"leading whitespace": {
+			text:  "    indented text goes here",
+			width: 12,
+			want:  "    indented\ntext goes\nhere",
+		},
+		"existing line breaks": {
+			text:   "one two\nthree four five",
+			width:  9,
+			indent: "- ",
+			want:   "one two\nthree\n- four\n- five",
+		},
+		"hanging indent": {
+			text:   "the quick brown fox jumps over the lazy dog",
+			width:  15,
+			indent: "  ",
+			want:   "the quick brown\n  fox jumps\n  over the lazy\n  dog",
+		},
+		"hanging indent with a long word": {
+			text:   "ab verylongword tail",
+			width:  12,
+			indent: "  ",
+			want:   "ab\n  verylongwo\n  rd tail",
+		},
+		"hanging indent wider than the first word": {
+			text:   "ab verylongword tail",
+			width:  12,
+			indent: "        ",
+			want:   "ab\n        very\n        long\n        word\n        tail",
+		},
+		"indent too wide to use": {
+			text:   "a b",
+			width:  1,
+			indent: "    ",
+			want:   "a\nb",
+		},
+		"CJK": {
+			text:  "これは日本語の文章です。とても長いので折り返されます。",
+			width: 20,
+			want:  "これは日本語の文章で\nす。とても長いので折\nり返されます。",
+		},
+		"CJK wider than the line": {
+			text:  "日本語",
+			width: 1,
+			want:  "日\n本\n語",
+		},
+		"CJK with one column left after the indent": {
+			text:   "ab 日本語です",
+			width:  5,
+			indent: "    ",
+			want:   "ab 日\n    本\n    語\n    で\n    す",
+		},
+		"ANSI escapes": {
+			text:  "\x1b[1mbold\x1b[0m text that wraps here",
+			width: 10,
+			want:  "\x1b[1mbold\x1b[0m text\nthat wraps\nhere",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			done := make(chan string, 1)
+			go func() {
+				done <- Wrap(test.text, test.width, test.indent)
+			}()
+
+			select {
+			case got := <-done:
+				if got != test.want {
+					t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Wrap did not return")
+			}
+		})
+	}
+}
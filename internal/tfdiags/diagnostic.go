@@ -0,0 +1,41 @@
+package tfdiags
+
+import (
+	"fmt"
+)
+
+type Severity rune
+
+const (
+	Error   Severity = 'E'
+	Warning Severity = 'W'
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "Error"
+	case Warning:
+		return "Warning"
+	default:
+		return fmt.Sprintf("Severity(%q)", rune(s))
+	}
+}
+
+type Diagnostic struct {
+	Severity Severity
+	Summary  string
+	Detail   string
+
+	// Subject is the portion of a source file that the diagnostic is
+	// about, or nil if it is not associated with any particular source.
+	Subject *SourceRange
+}
+
+func Sourceless(severity Severity, summary, detail string) Diagnostic {
+	return Diagnostic{
+		Severity: severity,
+		Summary:  summary,
+		Detail:   detail,
+	}
+}
@@ -0,0 +1,67 @@
+package tfdiags
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"strings"
+)
+
+type Diagnostics []Diagnostic
+
+func (diags Diagnostics) Append(new ...interface{}) Diagnostics {
+	for _, item := range new {
+		if item == nil {
+			continue
+		}
+
+		switch ti := item.(type) {
+		case Diagnostic:
+			diags = append(diags, ti)
+		case Diagnostics:
+			diags = append(diags, ti...)
+		case *hcl.Diagnostic:
+			diags = append(diags, fromHCLDiagnostic(ti))
+		case hcl.Diagnostics:
+			for _, hclDiag := range ti {
+				diags = append(diags, fromHCLDiagnostic(hclDiag))
+			}
+		case error:
+			diags = append(diags, Sourceless(Error, ti.Error(), ""))
+		default:
+			panic(fmt.Errorf("can't construct diagnostic(s) from %T", item))
+		}
+	}
+	return diags
+}
+
+func (diags Diagnostics) HasErrors() bool {
+	for _, diag := range diags {
+		if diag.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+func (diags Diagnostics) Err() error {
+	if !diags.HasErrors() {
+		return nil
+	}
+
+	var msgs []string
+	for _, diag := range diags {
+		if diag.Severity != Error {
+			continue
+		}
+		msg := diag.Summary
+		if diag.Detail != "" {
+			msg = fmt.Sprintf("%s: %s", msg, diag.Detail)
+		}
+		if diag.Subject != nil {
+			msg = fmt.Sprintf("%s: %s", diag.Subject, msg)
+		}
+		msgs = append(msgs, msg)
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
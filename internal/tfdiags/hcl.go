@@ -0,0 +1,36 @@
+package tfdiags
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+func fromHCLDiagnostic(diag *hcl.Diagnostic) Diagnostic {
+	ret := Diagnostic{
+		Severity: Error,
+		Summary:  diag.Summary,
+		Detail:   diag.Detail,
+	}
+	if diag.Severity == hcl.DiagWarning {
+		ret.Severity = Warning
+	}
+	if diag.Subject != nil {
+		ret.Subject = SourceRangeFromHCL(*diag.Subject)
+	}
+	return ret
+}
+
+func SourceRangeFromHCL(rng hcl.Range) *SourceRange {
+	return &SourceRange{
+		Filename: rng.Filename,
+		Start: SourcePos{
+			Line:   rng.Start.Line,
+			Column: rng.Start.Column,
+			Byte:   rng.Start.Byte,
+		},
+		End: SourcePos{
+			Line:   rng.End.Line,
+			Column: rng.End.Column,
+			Byte:   rng.End.Byte,
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package tfdiags
+
+import (
+	"fmt"
+	"strings"
+)
+
+type SourcePos struct {
+	Line, Column, Byte int
+}
+
+// SourceRange is a half-open range of bytes within a named source file.
+// Line and Column are 1-based and intended for display, while Byte is the
+// 0-based offset from the start of the file.
+type SourceRange struct {
+	Filename   string
+	Start, End SourcePos
+}
+
+func (r SourceRange) String() string {
+	if r.Start.Line == r.End.Line {
+		return fmt.Sprintf("%s:%d,%d-%d", r.Filename, r.Start.Line, r.Start.Column, r.End.Column)
+	}
+	return fmt.Sprintf("%s:%d,%d-%d,%d", r.Filename, r.Start.Line, r.Start.Column, r.End.Line, r.End.Column)
+}
+
+type SourceLine struct {
+	Number int
+	Text   string
+
+	// HighlightStart and HighlightEnd are byte offsets into Text covering
+	// the part of the line that falls within the range.
+	HighlightStart, HighlightEnd int
+}
+
+func (r SourceRange) SourceLines(src []byte) []SourceLine {
+	var ret []SourceLine
+	lineStart := 0
+	for number := 1; lineStart <= len(src); number++ {
+		lineEnd := lineStart
+		for lineEnd < len(src) && src[lineEnd] != '\n' {
+			lineEnd++
+		}
+
+		if number >= r.Start.Line && number <= r.End.Line {
+			text := strings.TrimSuffix(string(src[lineStart:lineEnd]), "\r")
+			hlStart := clampOffset(r.Start.Byte-lineStart, len(text))
+			hlEnd := clampOffset(r.End.Byte-lineStart, len(text))
+			ret = append(ret, SourceLine{
+				Number:         number,
+				Text:           text,
+				HighlightStart: hlStart,
+				HighlightEnd:   hlEnd,
+			})
+		}
+		if number >= r.End.Line {
+			break
+		}
+		lineStart = lineEnd + 1
+	}
+	return ret
+}
+
+func clampOffset(offset, max int) int {
+	switch {
+	case offset < 0:
+		return 0
+	case offset > max:
+		return max
+	default:
+		return offset
+	}
+}
@@ -0,0 +1,108 @@
+package tfdiags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSourceRangeSourceLines(t *testing.T) {
+	src := []byte("resource \"a\" \"b\" {\r\n  name = \"x\"\r\n}\r\n")
+
+	tests := map[string]struct {
+		src  []byte
+		rng  SourceRange
+		want []SourceLine
+	}{
+		"single line": {
+			src: []byte("one\ntwo three\nfour\n"),
+			rng: SourceRange{
+				Start: SourcePos{Line: 2, Column: 5, Byte: 8},
+				End:   SourcePos{Line: 2, Column: 10, Byte: 13},
+			},
+			want: []SourceLine{
+				{Number: 2, Text: "two three", HighlightStart: 4, HighlightEnd: 9},
+			},
+		},
+		"multiple lines": {
+			src: []byte("one\ntwo three\nfour\nfive\n"),
+			rng: SourceRange{
+				Start: SourcePos{Line: 2, Column: 5, Byte: 8},
+				End:   SourcePos{Line: 3, Column: 3, Byte: 16},
+			},
+			want: []SourceLine{
+				{Number: 2, Text: "two three", HighlightStart: 4, HighlightEnd: 9},
+				{Number: 3, Text: "four", HighlightStart: 0, HighlightEnd: 2},
+			},
+		},
+		"CRLF line endings": {
+			src: src,
+			rng: SourceRange{
+				Start: SourcePos{Line: 1, Column: 1, Byte: 0},
+				End:   SourcePos{Line: 2, Column: 13, Byte: 32},
+			},
+			want: []SourceLine{
+				{Number: 1, Text: "resource \"a\" \"b\" {", HighlightStart: 0, HighlightEnd: 18},
+				{Number: 2, Text: "  name = \"x\"", HighlightStart: 0, HighlightEnd: 12},
+			},
+		},
+		"offsets beyond the line are clamped": {
+			src: []byte("short\nline\n"),
+			rng: SourceRange{
+				Start: SourcePos{Line: 1, Column: 3, Byte: 2},
+				End:   SourcePos{Line: 1, Column: 50, Byte: 49},
+			},
+			want: []SourceLine{
+				{Number: 1, Text: "short", HighlightStart: 2, HighlightEnd: 5},
+			},
+		},
+		"last line without a line ending": {
+			src: []byte("one\ntwo"),
+			rng: SourceRange{
+				Start: SourcePos{Line: 2, Column: 1, Byte: 4},
+				End:   SourcePos{Line: 2, Column: 4, Byte: 7},
+			},
+			want: []SourceLine{
+				{Number: 2, Text: "two", HighlightStart: 0, HighlightEnd: 3},
+			},
+		},
+		"range past the end of the file": {
+			src: []byte("one\n"),
+			rng: SourceRange{
+				Start: SourcePos{Line: 5, Column: 1, Byte: 20},
+				End:   SourcePos{Line: 5, Column: 2, Byte: 21},
+			},
+			want: nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := test.rng.SourceLines(test.src)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSourceRangeString(t *testing.T) {
+	tests := []struct {
+		rng  SourceRange
+		want string
+	}{
+		{
+			SourceRange{Filename: "main.tf", Start: SourcePos{Line: 2, Column: 3}, End: SourcePos{Line: 2, Column: 8}},
+			"main.tf:2,3-8",
+		},
+		{
+			SourceRange{Filename: "main.tf", Start: SourcePos{Line: 2, Column: 3}, End: SourcePos{Line: 4, Column: 1}},
+			"main.tf:2,3-4,1",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.rng.String(); got != test.want {
+			t.Errorf("wrong result %q; want %q", got, test.want)
+		}
+	}
+}
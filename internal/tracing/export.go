@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+)
+
+const (
+	serviceName = "terraform"
+	scopeName   = "github.com/IkezawaYuki/lucky-strike"
+
+	otlpSpanKindInternal = 1
+	otlpStatusCodeOK     = 1
+	otlpStatusCodeError  = 2
+)
+
+type otlpExport struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// Shutdown writes every finished span to the file named by TF_TRACE_PATH
+// as OTLP/JSON, in the format accepted by the OpenTelemetry collector's
+// file receiver and by most trace viewers.
+func Shutdown() error {
+	if !enabled {
+		return nil
+	}
+
+	mu.Lock()
+	spans := finished
+	finished = nil
+	mu.Unlock()
+
+	export := otlpExport{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: []otlpKeyValue{otlpAttribute("service.name", serviceName)},
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: scopeName},
+						Spans: make([]otlpSpan, 0, len(spans)),
+					},
+				},
+			},
+		},
+	}
+	for _, s := range spans {
+		export.ResourceSpans[0].ScopeSpans[0].Spans = append(export.ResourceSpans[0].ScopeSpans[0].Spans, s.otlp())
+	}
+
+	src, err := json.Marshal(export)
+	if err != nil {
+		return err
+	}
+	path := os.Getenv(envTracePath)
+	if err := ioutil.WriteFile(path, append(src, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write trace file %s: %s", path, err)
+	}
+	return nil
+}
+
+func (s *Span) otlp() otlpSpan {
+	ret := otlpSpan{
+		TraceID:           s.traceID.String(),
+		SpanID:            s.spanID.String(),
+		Name:              s.name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: otlpStatusCodeOK},
+	}
+	if s.hasParent {
+		ret.ParentSpanID = s.parentID.String()
+	}
+	for _, attr := range s.attributes {
+		ret.Attributes = append(ret.Attributes, otlpAttribute(attr.key, attr.value))
+	}
+	if s.err != nil {
+		ret.Status = otlpStatus{
+			Code:    otlpStatusCodeError,
+			Message: s.err.Error(),
+		}
+	}
+	return ret
+}
+
+func otlpAttribute(key string, value interface{}) otlpKeyValue {
+	var v otlpAnyValue
+	switch tv := value.(type) {
+	case string:
+		v.StringValue = &tv
+	case bool:
+		v.BoolValue = &tv
+	case int:
+		s := strconv.FormatInt(int64(tv), 10)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(tv, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &tv
+	default:
+		s := fmt.Sprint(tv)
+		v.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+)
+
+const envTracePath = "TF_TRACE_PATH"
+
+var (
+	mu       sync.Mutex
+	enabled  = os.Getenv(envTracePath) != ""
+	finished []*Span
+	root     *Span
+)
+
+type traceID [16]byte
+
+type spanID [8]byte
+
+type Span struct {
+	name       string
+	traceID    traceID
+	spanID     spanID
+	parentID   spanID
+	hasParent  bool
+	start, end time.Time
+	attributes []attribute
+	err        error
+}
+
+type attribute struct {
+	key   string
+	value interface{}
+}
+
+type spanContextKey struct{}
+
+// Start begins a span that is a child of the span in ctx or, if ctx has
+// none, of the root span started by StartRoot. When tracing is disabled it
+// returns ctx unchanged and a nil span, whose methods all do nothing.
+func Start(ctx context.Context, name string, attrs ...interface{}) (context.Context, *Span) {
+	if !enabled {
+		return ctx, nil
+	}
+
+	parent, _ := ctx.Value(spanContextKey{}).(*Span)
+	if parent == nil {
+		mu.Lock()
+		parent = root
+		mu.Unlock()
+	}
+
+	span := &Span{
+		name:  name,
+		start: time.Now(),
+	}
+	if parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+		span.hasParent = true
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+	span.SetAttributes(attrs...)
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// StartRoot begins the span that every span started without a parent in
+// its context belongs to, typically covering a whole command.
+func StartRoot(name string, attrs ...interface{}) (context.Context, *Span) {
+	ctx, span := Start(context.Background(), name, attrs...)
+	if span != nil {
+		mu.Lock()
+		root = span
+		mu.Unlock()
+	}
+	return ctx, span
+}
+
+// SetAttributes records alternating key and value arguments on the span,
+// in the same style as hclog.
+func (s *Span) SetAttributes(attrs ...interface{}) {
+	if s == nil {
+		return
+	}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		key, ok := attrs[i].(string)
+		if !ok {
+			continue
+		}
+		s.attributes = append(s.attributes, attribute{key: key, value: attrs[i+1]})
+	}
+}
+
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err
+}
+
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+
+	mu.Lock()
+	defer mu.Unlock()
+	finished = append(finished, s)
+}
+
+func (id traceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id spanID) String() string {
+	return hex.EncodeToString(id[:])
+}
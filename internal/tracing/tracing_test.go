@@ -0,0 +1,187 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// enableTracing turns tracing on for the rest of the test, writing to a
+// new temporary file, and returns its path and a function that restores
+// the previous state.
+func enableTracing(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "tracing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "trace.json")
+
+	oldPath, hadPath := os.LookupEnv(envTracePath)
+	oldEnabled, oldRoot := enabled, root
+	os.Setenv(envTracePath, path)
+	enabled, root, finished = true, nil, nil
+
+	return path, func() {
+		if hadPath {
+			os.Setenv(envTracePath, oldPath)
+		} else {
+			os.Unsetenv(envTracePath)
+		}
+		enabled, root, finished = oldEnabled, oldRoot, nil
+		os.RemoveAll(dir)
+	}
+}
+
+type testExport struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []testKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			Spans []testSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type testSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []testKeyValue `json:"attributes"`
+	Status            struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type testKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func TestShutdown(t *testing.T) {
+	path, restore := enableTracing(t)
+	defer restore()
+
+	ctx, rootSpan := StartRoot("terraform", "command", "version")
+	_, child := Start(ctx, "version.check", "attempt", 1)
+	child.RecordError(errors.New("release index unavailable"))
+	child.End()
+	_, orphan := Start(context.Background(), "getproviders.AvailableVersions")
+	orphan.End()
+	rootSpan.End()
+
+	if err := Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got testExport
+	if err := json.Unmarshal(src, &got); err != nil {
+		t.Fatalf("trace file is not JSON: %s\n%s", err, src)
+	}
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("wrong OTLP structure:\n%s", src)
+	}
+	resource := got.ResourceSpans[0].Resource
+	if len(resource.Attributes) != 1 || resource.Attributes[0].Key != "service.name" || resource.Attributes[0].Value["stringValue"] != "terraform" {
+		t.Errorf("wrong resource attributes %#v", resource.Attributes)
+	}
+	scope := got.ResourceSpans[0].ScopeSpans[0]
+	if scope.Scope.Name != scopeName {
+		t.Errorf("wrong scope name %q", scope.Scope.Name)
+	}
+
+	spans := scope.Spans
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans; want 3\n%s", len(spans), src)
+	}
+	childGot, orphanGot, rootGot := spans[0], spans[1], spans[2]
+
+	if rootGot.Name != "terraform" || childGot.Name != "version.check" || orphanGot.Name != "getproviders.AvailableVersions" {
+		t.Fatalf("wrong span names %q, %q, %q", childGot.Name, orphanGot.Name, rootGot.Name)
+	}
+	if len(rootGot.TraceID) != 32 || len(rootGot.SpanID) != 16 {
+		t.Errorf("wrong ID lengths: trace %q, span %q", rootGot.TraceID, rootGot.SpanID)
+	}
+	if rootGot.ParentSpanID != "" {
+		t.Errorf("root span has parent %q", rootGot.ParentSpanID)
+	}
+	for _, s := range []testSpan{childGot, orphanGot} {
+		if s.TraceID != rootGot.TraceID {
+			t.Errorf("%s has trace %q; want %q", s.Name, s.TraceID, rootGot.TraceID)
+		}
+		if s.ParentSpanID != rootGot.SpanID {
+			t.Errorf("%s has parent %q; want %q", s.Name, s.ParentSpanID, rootGot.SpanID)
+		}
+		if s.SpanID == rootGot.SpanID {
+			t.Errorf("%s has the same span ID as the root", s.Name)
+		}
+	}
+
+	if childGot.Status.Code != otlpStatusCodeError || childGot.Status.Message != "release index unavailable" {
+		t.Errorf("wrong child status %#v", childGot.Status)
+	}
+	if rootGot.Status.Code != otlpStatusCodeOK || rootGot.Status.Message != "" {
+		t.Errorf("wrong root status %#v", rootGot.Status)
+	}
+	if len(childGot.Attributes) != 1 || childGot.Attributes[0].Key != "attempt" || childGot.Attributes[0].Value["intValue"] != "1" {
+		t.Errorf("wrong child attributes %#v", childGot.Attributes)
+	}
+	if rootGot.Kind != otlpSpanKindInternal {
+		t.Errorf("wrong kind %d", rootGot.Kind)
+	}
+	start, err := strconv.ParseInt(rootGot.StartTimeUnixNano, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, err := strconv.ParseInt(rootGot.EndTimeUnixNano, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start == 0 || end < start {
+		t.Errorf("root span ends at %d, before it starts at %d", end, start)
+	}
+}
+
+func TestStart_disabled(t *testing.T) {
+	path, restore := enableTracing(t)
+	defer restore()
+	enabled = false
+
+	ctx := context.Background()
+	gotCtx, span := Start(ctx, "terraform")
+	if span != nil {
+		t.Fatalf("got span %#v while disabled", span)
+	}
+	if gotCtx != ctx {
+		t.Errorf("context changed while disabled")
+	}
+
+	// The methods of a nil span do nothing.
+	span.SetAttributes("key", "value")
+	span.RecordError(errors.New("boom"))
+	span.End()
+
+	if err := Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("trace file written while disabled: %v", err)
+	}
+}
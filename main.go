@@ -1,14 +1,30 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/IkezawaYuki/lucky-strike/internal/command"
+	"github.com/IkezawaYuki/lucky-strike/internal/command/format"
+	viewsjson "github.com/IkezawaYuki/lucky-strike/internal/command/views/json"
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
 	"github.com/IkezawaYuki/lucky-strike/internal/logging"
 	"github.com/IkezawaYuki/lucky-strike/internal/terminal"
+	"github.com/IkezawaYuki/lucky-strike/internal/tfdiags"
+	"github.com/IkezawaYuki/lucky-strike/internal/tracing"
+	"github.com/hashicorp/terraform-svchost/disco"
+	"github.com/hashicorp/terraform/command/cliconfig"
+	tfdiagsupstream "github.com/hashicorp/terraform/tfdiags"
 	"github.com/hashicorp/terraform/version"
+	"github.com/mitchellh/cli"
+	"github.com/mitchellh/colorstring"
 	"github.com/mitchellh/panicwrap"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
 	"runtime"
+	"strings"
 )
 
 var Version = version.Version
@@ -17,7 +33,9 @@ var VersionPrerelease = version.Prerelease
 
 const (
 	envTempLogPath                 = "TF_TEMP_LOG_PATH"
+	envPluginPanicLogPath          = "TF_PLUGIN_PANIC_LOG_PATH"
 	envTerminalPanicwrapWorkaround = "TF_PANICWRAP_STDERR"
+	envNoColor                     = "NO_COLOR"
 )
 
 func main() {
@@ -27,12 +45,77 @@ func main() {
 func realMain() int {
 	var wrapConfig panicwrap.WrapConfig
 
+	// The CLI configuration is loaded in both the panicwrap parent and the
+	// child, so that credentials tokens are redacted from everything either
+	// process logs, including the crash log written by the parent.
+	config, configDiags := cliconfig.LoadConfig()
+	for _, creds := range config.Credentials {
+		if token, ok := creds["token"].(string); ok {
+			logging.RegisterSecret(token)
+		}
+	}
+
 	if os.Getenv("TF_FORK") == "0" {
-		return wrappedMain()
+		return wrappedMain(config, configDiags)
+	}
+
+	if !panicwrap.Wrapped(&wrapConfig) {
+		logTempFile, err := ioutil.TempFile("", "terraform-log")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Couldn't set up logging tempfile: %s", err)
+			return 1
+		}
+		logTempFile.Close()
+		defer os.Remove(logTempFile.Name())
+		os.Setenv(envTempLogPath, logTempFile.Name())
+
+		pluginPanicFile, err := ioutil.TempFile("", "terraform-plugin-panics")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Couldn't set up plugin panic tempfile: %s", err)
+			return 1
+		}
+		pluginPanicFile.Close()
+		defer os.Remove(pluginPanicFile.Name())
+		os.Setenv(envPluginPanicLogPath, pluginPanicFile.Name())
+
+		streams, err := terminal.Init()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize terminal: %s", err)
+			return 1
+		}
+		defer streams.Restore()
+		streamState := streams.StateForAfterPanicWrap()
+		os.Setenv(envTerminalPanicwrapWorkaround, fmt.Sprintf("%t:%d:%d:%d", streamState.StderrIsTerminal, streamState.StderrWidth, streamState.StderrColorDepth, streamState.StderrHeight))
+
+		wrapConfig.Handler = logging.PanicHandler(logTempFile.Name(), pluginPanicFile.Name(), logging.CrashInfo{
+			Version:  versionString(),
+			Platform: getproviders.CurrentPlatform.String(),
+			Args:     os.Args,
+		})
+		wrapConfig.IgnoreSignals = ignoreSignals
+		wrapConfig.ForwardSignals = forwardSignals
+		exitStatus, err := panicwrap.Wrap(&wrapConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Couldn't start Terraform: %s", err)
+			return 1
+		}
+
+		if exitStatus >= 0 {
+			return exitStatus
+		}
 	}
+
+	return wrappedMain(config, configDiags)
 }
 
-func wrappedMain() int {
+func versionString() string {
+	if VersionPrerelease != "" {
+		return Version + "-" + VersionPrerelease
+	}
+	return Version
+}
+
+func wrappedMain(config *cliconfig.Config, configDiags tfdiagsupstream.Diagnostics) int {
 	var err error
 
 	tmpLogPath := os.Getenv(envTempLogPath)
@@ -48,6 +131,28 @@ func wrappedMain() int {
 		}
 	}
 
+	if pluginPanicLogPath := os.Getenv(envPluginPanicLogPath); pluginPanicLogPath != "" {
+		f, err := os.OpenFile(pluginPanicLogPath, os.O_WRONLY|os.O_APPEND, 0600)
+		if err == nil {
+			defer f.Close()
+
+			log.Printf("[DEBUG] Adding plugin panic sink: %s", f.Name())
+			logging.RegisterPluginPanicSink(f)
+		} else {
+			log.Printf("[ERROR] Could not open plugin panic log file: %v", err)
+		}
+	}
+
+	defer logging.ListenForLevelSignals()()
+
+	defer func() {
+		if err := tracing.Shutdown(); err != nil {
+			log.Printf("[ERROR] %s", err)
+		}
+	}()
+	ctx, span := tracing.StartRoot("terraform")
+	defer span.End()
+
 	log.Printf("[INFO] Terraform version: %s %s",
 		Version, VersionPrerelease)
 	log.Printf("[INFO] Go runtime version: %s", runtime.Version())
@@ -56,13 +161,177 @@ func wrappedMain() int {
 	var streamState *terminal.PrePanicwrapState
 	if raw := os.Getenv(envTerminalPanicwrapWorkaround); raw != "" {
 		streamState = &terminal.PrePanicwrapState{}
-		if _, err := fmt.Sscan(raw, "%t:%d", &streamState.StderrIsTerminal, &streamState.StderrWidth); err != nil {
+		if _, err := fmt.Sscanf(raw, "%t:%d:%d:%d", &streamState.StderrIsTerminal, &streamState.StderrWidth, &streamState.StderrColorDepth, &streamState.StderrHeight); err != nil {
 			log.Printf("[WARN] %s is set but is incorrectly-formatted: %s", envTerminalPanicwrapWorkaround, err)
 			streamState = nil
 		}
-		streams, err := terminal.ReinitInsidePanicwrap(streamState)
-		if err != nil {
-			Ui.Error
+	}
+	streams, err := terminal.ReinitInsidePanicwrap(streamState)
+	if err != nil {
+		Ui.Error(fmt.Sprintf("Failed to configure the terminal: %s", err))
+		return 1
+	}
+	defer streams.Restore()
+	Ui = &cli.BasicUi{
+		Writer:      streams.Stdout.File,
+		ErrorWriter: streams.Stderr.File,
+		Reader:      streams.Stdin.File,
+	}
+
+	if len(configDiags) > 0 {
+		Ui.Error("There are some problems with the CLI configuration:")
+		earlyColor := &colorstring.Colorize{
+			Colors:  colorstring.DefaultColors,
+			Disable: true,
+			Reset:   true,
+		}
+		for _, diag := range configDiags {
+			desc := diag.Description()
+			severity := tfdiags.Error
+			if diag.Severity() == tfdiagsupstream.Warning {
+				severity = tfdiags.Warning
+			}
+			early := tfdiags.Sourceless(severity, desc.Summary, desc.Detail)
+			Ui.Error(format.Diagnostic(early, nil, earlyColor, streams.Stderr.Columns()))
+		}
+		if configDiags.HasErrors() {
+			Ui.Error("As a result of the above problems, Terraform may not behave as intended.\n\n")
 		}
 	}
+
+	var services *disco.Disco
+	credsSrc, err := credentialsSource(config)
+	if err == nil {
+		services = disco.NewWithCredentialsSource(credsSrc)
+	} else {
+		log.Printf("[WARN] Cannot initialize remote host credentials manager: %s", err)
+		services = disco.NewWithCredentialsSource(nil)
+	}
+
+	binName := filepath.Base(os.Args[0])
+	args := os.Args[1:]
+
+	noColor, args := extractBoolOption("no-color", args)
+	if os.Getenv(envNoColor) != "" {
+		noColor = true
+	}
+
+	noPager, args := extractBoolOption("no-pager", args)
+
+	inAutomation := os.Getenv(runningInAutomationEnvMode) != ""
+	jsonOutput, args := extractBoolOption("json", args)
+	if jsonOutput {
+		Ui = command.NewJSONUi(streams, viewsjson.Version{
+			Version:   versionString(),
+			Platform:  getproviders.CurrentPlatform.String(),
+			GoRuntime: runtime.Version(),
+		})
+	} else {
+		Ui = command.NewStreamsUi(streams, !noColor && !inAutomation, inAutomation)
+	}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		Ui.Error(fmt.Sprintf("Failed to determine current working directory: %s", err))
+		return 1
+	}
+
+	if Commands == nil {
+		initCommands(ctx, originalWd, streams, config, services, inAutomation, noColor, noPager || jsonOutput)
+	}
+
+	for _, arg := range args {
+		if arg == "-v" || arg == "-version" || arg == "--version" {
+			newArgs := make([]string, len(args)+1)
+			newArgs[0] = "version"
+			copy(newArgs[1:], args)
+			args = newArgs
+			break
+		}
+	}
+
+	log.Printf("[INFO] CLI command args: %#v", args)
+	var helpBuf bytes.Buffer
+	cliRunner := &cli.CLI{
+		Name:        binName,
+		Args:        args,
+		Commands:    Commands,
+		HelpWriter:  &helpBuf,
+		ErrorWriter: streams.Stderr.File,
+	}
+
+	span.SetAttributes("command", cliRunner.Subcommand(), "args", strings.Join(cliRunner.SubcommandArgs(), " "))
+	exitCode, err := cliRunner.Run()
+	span.SetAttributes("exit_code", exitCode)
+	if jsonUi, ok := Ui.(*command.JSONUi); ok {
+		jsonUi.EnsureVersion()
+	}
+	switch {
+	case helpBuf.Len() == 0:
+	case jsonOutput:
+		// Every line of a JSON stream must be a message, so the help text
+		// is sent as one.
+		Ui.Output(strings.TrimRight(helpBuf.String(), "\n"))
+	default:
+		help := wrapHelp(helpBuf.String(), streams.Stdout.Columns())
+		if err := streams.Page(help, !noPager && !inAutomation); err != nil {
+			log.Printf("[WARN] Failed to run pager: %s", err)
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		Ui.Error(fmt.Sprintf("Error executing CLI: %s", err.Error()))
+		return 1
+	}
+
+	if exitCode != 0 {
+		for _, panicLog := range logging.PluginPanics() {
+			Ui.Error(panicLog)
+		}
+	}
+
+	return exitCode
+}
+
+// helpOptionLine matches a line of help text that describes an option, up
+// to the start of the description.
+var helpOptionLine = regexp.MustCompile(`^(\s*-\S+\s{2,})\S`)
+
+// wrapHelp wraps help text to the given width, continuing each line at its
+// own indentation, or at the start of the description for a line
+// describing an option, so that option lists stay aligned.
+func wrapHelp(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+		if match := helpOptionLine.FindStringSubmatch(line); match != nil {
+			indent = strings.Repeat(" ", terminal.StringWidth(match[1]))
+		}
+		lines[i] = terminal.Wrap(line, width, indent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func extractBoolOption(name string, args []string) (bool, []string) {
+	flag := "-" + name
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			break
+		}
+		if arg == flag || arg == "-"+flag {
+			ret := make([]string, 0, len(args)-1)
+			ret = append(ret, args[:i]...)
+			ret = append(ret, args[i+1:]...)
+			return true, ret
+		}
+	}
+	return false, args
+}
+
+func init() {
+	Ui = &cli.BasicUi{
+		Writer:      os.Stdout,
+		ErrorWriter: os.Stderr,
+		Reader:      os.Stdin,
+	}
 }
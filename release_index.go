@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/IkezawaYuki/lucky-strike/internal/command"
+	"github.com/IkezawaYuki/lucky-strike/internal/getproviders"
+	"github.com/IkezawaYuki/lucky-strike/internal/tracing"
+	"github.com/hashicorp/terraform/httpclient"
+	"net/http"
+	"os"
+)
+
+const (
+	envReleaseIndexURL     = "TF_RELEASE_INDEX_URL"
+	defaultReleaseIndexURL = "https://releases.hashicorp.com/terraform/index.json"
+)
+
+type releaseIndex struct {
+	Versions map[string]json.RawMessage `json:"versions"`
+}
+
+func releaseIndexCheck(current string) command.VersionCheckFunc {
+	return func(ctx context.Context) (info command.VersionCheckInfo, err error) {
+		ctx, span := tracing.Start(ctx, "version.check")
+		defer func() {
+			span.RecordError(err)
+			span.End()
+		}()
+
+		currentVersion, err := getproviders.ParseVersion(current)
+		if err != nil {
+			return info, fmt.Errorf("invalid current version %q: %s", current, err)
+		}
+
+		url := os.Getenv(envReleaseIndexURL)
+		if url == "" {
+			url = defaultReleaseIndexURL
+		}
+
+		span.SetAttributes("url", url)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return info, fmt.Errorf("invalid release index URL %q: %s", url, err)
+		}
+		resp, err := httpclient.New().Do(req)
+		if err != nil {
+			return info, fmt.Errorf("failed to fetch release index: %s", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return info, fmt.Errorf("failed to fetch release index from %s: %s", url, resp.Status)
+		}
+
+		var index releaseIndex
+		if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+			return info, fmt.Errorf("invalid release index at %s: %s", url, err)
+		}
+
+		latest := getproviders.UnspecifiedVersion
+		for raw := range index.Versions {
+			v, err := getproviders.ParseVersion(raw)
+			if err != nil || v.Prerelease != "" {
+				continue
+			}
+			if v.GreaterThan(latest) {
+				latest = v
+			}
+		}
+		if latest == getproviders.UnspecifiedVersion {
+			return info, fmt.Errorf("release index at %s lists no releases", url)
+		}
+
+		info.Latest = latest.String()
+		info.Outdated = latest.GreaterThan(currentVersion)
+		return info, nil
+	}
+}
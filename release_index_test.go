@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReleaseIndexCheck(t *testing.T) {
+	index := `{"name": "terraform", "versions": {
+		"0.14.7": {}, "0.14.8": {}, "0.15.0-beta1": {}, "not-a-version": {}
+	}}`
+
+	tests := map[string]struct {
+		current      string
+		status       int
+		body         string
+		wantLatest   string
+		wantOutdated bool
+		wantErr      string
+	}{
+		"up to date": {
+			current:    "0.14.8",
+			status:     http.StatusOK,
+			body:       index,
+			wantLatest: "0.14.8",
+		},
+		"outdated": {
+			current:      "0.14.7",
+			status:       http.StatusOK,
+			body:         index,
+			wantLatest:   "0.14.8",
+			wantOutdated: true,
+		},
+		"prerelease of the latest release": {
+			current:      "0.14.8-rc1",
+			status:       http.StatusOK,
+			body:         index,
+			wantLatest:   "0.14.8",
+			wantOutdated: true,
+		},
+		"newer than the latest release": {
+			current:    "0.15.0-dev",
+			status:     http.StatusOK,
+			body:       index,
+			wantLatest: "0.14.8",
+		},
+		"server error": {
+			current: "0.14.8",
+			status:  http.StatusInternalServerError,
+			wantErr: "500 Internal Server Error",
+		},
+		"invalid index": {
+			current: "0.14.8",
+			status:  http.StatusOK,
+			body:    "<html>",
+			wantErr: "invalid release index",
+		},
+		"no releases": {
+			current: "0.14.8",
+			status:  http.StatusOK,
+			body:    `{"versions": {"0.15.0-beta1": {}}}`,
+			wantErr: "lists no releases",
+		},
+		"invalid current version": {
+			current: "dev",
+			status:  http.StatusOK,
+			body:    index,
+			wantErr: `invalid current version "dev"`,
+		},
+	}
+
+	defer os.Unsetenv(envReleaseIndexURL)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			os.Setenv(envReleaseIndexURL, server.URL)
+
+			info, err := releaseIndexCheck(test.current)(context.Background())
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("wrong error %v; want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if info.Latest != test.wantLatest {
+				t.Errorf("wrong latest version %q; want %q", info.Latest, test.wantLatest)
+			}
+			if info.Outdated != test.wantOutdated {
+				t.Errorf("wrong outdated %t; want %t", info.Outdated, test.wantOutdated)
+			}
+		})
+	}
+}